@@ -84,6 +84,7 @@ func AddCommands(topLevel *cobra.Command) {
 	addExport(topLevel)
 	addUpgrade(topLevel)
 	addSetVersion(topLevel)
+	addDiscoverActions(topLevel)
 }
 
 func initLogging(*cobra.Command, []string) error {
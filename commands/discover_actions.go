@@ -0,0 +1,90 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+
+	"sigs.k8s.io/zeitgeist/dependency"
+)
+
+type discoverActionsOptions struct {
+	rootOpts   *options
+	dir        string
+	outputFile string
+}
+
+var discoverActionsOpts = &discoverActionsOptions{}
+
+func addDiscoverActions(topLevel *cobra.Command) {
+	dao := discoverActionsOpts
+	dao.rootOpts = rootOpts
+
+	cmd := &cobra.Command{
+		Use:           "discover-actions",
+		Short:         "Generate dependencies for the GitHub Actions used by workflows",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PreRunE: func(*cobra.Command, []string) error {
+			return dao.rootOpts.setAndValidate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runDiscoverActions(dao)
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&discoverActionsOpts.dir,
+		"dir",
+		".github",
+		"directory of the workflows and actions, relative to the base path",
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&discoverActionsOpts.outputFile,
+		"output-file",
+		"",
+		"file to write the dependencies to. If not specified, they are printed.",
+	)
+
+	topLevel.AddCommand(cmd)
+}
+
+// runDiscoverActions is the function invoked by 'addDiscoverActions',
+// responsible for generating dependencies for the actions used by workflows.
+func runDiscoverActions(opts *discoverActionsOptions) error {
+	dependencies, err := dependency.DiscoverActions(opts.rootOpts.basePath, opts.dir)
+	if err != nil {
+		return err
+	}
+
+	if opts.outputFile != "" {
+		return dependency.ToFile(opts.outputFile, dependencies)
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(dependencies); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return encoder.Close()
+}
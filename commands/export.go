@@ -137,6 +137,22 @@ func outputLog(updates []dependency.VersionUpdate) error {
 				update.Version,
 				update.NewVersion,
 			)
+		} else if update.HeldUntil != "" {
+			fmt.Printf(
+				"Dependency %v is held at version %v until %v: %v (latest: %v)\n",
+				update.Name,
+				update.Version,
+				update.HeldUntil,
+				update.HoldReason,
+				update.NewVersion,
+			)
+		} else if update.OutsidePolicy {
+			fmt.Printf(
+				"Update available for dependency %v outside of its auto-upgrade policy: %v (current: %v)\n",
+				update.Name,
+				update.NewVersion,
+				update.Version,
+			)
 		} else {
 			fmt.Printf(
 				"Update available for dependency %v: %v (current: %v)\n",
@@ -33,6 +33,9 @@ type options struct {
 
 	// command options
 	logLevel string
+
+	// validate options
+	verifyArtifacts bool
 }
 
 // setAndValidate sets some default options and verifies if options are valid.
@@ -40,6 +40,13 @@ func addValidate(topLevel *cobra.Command) {
 		},
 	}
 
+	cmd.Flags().BoolVar(
+		&vo.verifyArtifacts,
+		"verify-artifacts",
+		false,
+		"if specified, download the artifacts of dependencies and verify their checksums",
+	)
+
 	topLevel.AddCommand(cmd)
 }
 
@@ -63,6 +70,12 @@ func runValidate(opts *options) error {
 		return fmt.Errorf("checking local dependencies: %w", err)
 	}
 
+	if opts.verifyArtifacts {
+		if err := client.VerifyArtifacts(opts.configFile); err != nil {
+			return fmt.Errorf("verifying artifacts: %w", err)
+		}
+	}
+
 	if !opts.localOnly {
 		updates, err := client.RemoteCheck(opts.configFile)
 		if err != nil {
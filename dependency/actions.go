@@ -0,0 +1,180 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/blang/semver/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// actionUses matches `uses:` references to actions and reusable workflows
+	// of other repositories, e.g. `- uses: actions/checkout@<sha> # v4.2.2`,
+	// capturing the repository, the ref and the trailing comment.
+	actionUses = regexp.MustCompile(`^\s*(?:-\s+)?uses:\s*["']?([A-Za-z0-9_-][\w.-]*/[\w.-]+)(?:/[^@\s"']*)?@([^\s"'#]+)["']?(?:\s+#\s*(\S+))?`)
+
+	// actionSHA matches the full commit SHAs actions are pinned to.
+	actionSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// actionRef is a version of an action, and the files referencing it.
+type actionRef struct {
+	ref     string
+	comment string
+	paths   []string
+}
+
+// DiscoverActions finds the actions used by the workflows and actions in dir,
+// e.g. .github, and returns them as dependencies with github upstreams. Actions
+// pinned to a commit need a trailing version comment, which is kept in sync
+// with the commit by the commit companion. Paths are relative to basePath.
+func DiscoverActions(basePath, dir string) (*Dependencies, error) {
+	actions := make(map[string]*actionRef)
+	conflicting := make(map[string]bool)
+
+	err := filepath.WalkDir(filepath.Join(basePath, dir), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || (filepath.Ext(path) != ".yml" && filepath.Ext(path) != ".yaml") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(basePath, path)
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("Examining file: %s", path)
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			matches := actionUses.FindStringSubmatch(scanner.Text())
+			if matches == nil {
+				continue
+			}
+
+			repo, ref, comment := matches[1], matches[2], matches[3]
+			action, ok := actions[repo]
+			if !ok {
+				action = &actionRef{ref: ref, comment: comment}
+				actions[repo] = action
+			} else if action.ref != ref || action.comment != comment {
+				conflicting[repo] = true
+			}
+
+			if !slices.Contains(action.paths, relPath) {
+				action.paths = append(action.paths, relPath)
+			}
+		}
+
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discovering actions: %w", err)
+	}
+
+	dependencies := &Dependencies{}
+	for repo, action := range actions {
+		if conflicting[repo] {
+			log.Warnf("Skipping action %s, used at several versions: align them to add it", repo)
+			continue
+		}
+
+		dependency, err := actionDependency(repo, action)
+		if err != nil {
+			log.Warnf("Skipping action %s: %v", repo, err)
+			continue
+		}
+
+		dependencies.Dependencies = append(dependencies.Dependencies, dependency)
+	}
+
+	slices.SortFunc(dependencies.Dependencies, func(a, b *Dependency) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return dependencies, nil
+}
+
+// actionDependency returns the dependency on an action used at a version,
+// e.g. v4.2.2 or v4, or at a commit with a version comment.
+func actionDependency(repo string, action *actionRef) (*Dependency, error) {
+	version, pinned := action.ref, actionSHA.MatchString(action.ref)
+	if pinned {
+		if action.comment == "" {
+			return nil, fmt.Errorf("pinned to commit %s without a version comment", action.ref)
+		}
+		version = action.comment
+	}
+
+	if _, err := semver.ParseTolerant(version); err != nil {
+		return nil, fmt.Errorf("%s is not a version", version)
+	}
+
+	upstream := map[string]string{
+		"flavour": "github",
+		"url":     repo,
+	}
+
+	// Major or minor versions, e.g. v4, follow the latest release they cover
+	core, _, _ := strings.Cut(version, "-")
+	prefix := core[:strings.IndexFunc(core, unicode.IsDigit)]
+	switch strings.Count(core, ".") {
+	case 0:
+		upstream["versionTemplate"] = prefix + "{{ .Major }}"
+	case 1:
+		upstream["versionTemplate"] = prefix + "{{ .Major }}.{{ .Minor }}"
+	}
+
+	dependency := &Dependency{
+		Name:     repo,
+		Version:  version,
+		Scheme:   Semver,
+		Upstream: upstream,
+	}
+
+	match := `uses:\s*["']?` + regexp.QuoteMeta(repo) + `(/[^@\s"']*)?@`
+	if pinned {
+		upstream["pinCommit"] = "true"
+		dependency.Companions = map[string]string{"commit": action.ref}
+	}
+
+	for _, path := range action.paths {
+		dependency.RefPaths = append(dependency.RefPaths, &RefPath{Path: path, Match: match})
+		if pinned {
+			dependency.RefPaths = append(dependency.RefPaths, &RefPath{Path: path, Match: match, Companion: "commit"})
+		}
+	}
+
+	return dependency, nil
+}
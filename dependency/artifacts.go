@@ -0,0 +1,159 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Artifact is a file downloaded at the version of a dependency, e.g. a binary
+// release for an OS and architecture, whose checksum is pinned next to the
+// version.
+type Artifact struct {
+	// Optional: OS and architecture of the artifact, available in the URL template
+	OS   string `yaml:"os,omitempty"`
+	Arch string `yaml:"arch,omitempty"`
+	// URL of the artifact, as a template using the .Version of the dependency,
+	// the .OS and the .Arch, e.g. https://example.com/{{ .Version }}/tool_{{ .OS }}_{{ .Arch }}.tar.gz
+	URL string `yaml:"url"`
+	// SHA-256 checksum of the artifact at the current version
+	SHA256 string `yaml:"sha256"`
+	// List of references to the checksum in local files
+	RefPaths []*RefPath `yaml:"refPaths"`
+}
+
+// sha256Checksum matches SHA-256 checksums.
+var sha256Checksum = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// validate checks the settings of an artifact.
+func (a *Artifact) validate() error {
+	if a.URL == "" {
+		return errors.New("artifact is missing `url`")
+	}
+	if _, err := a.parseURL(); err != nil {
+		return err
+	}
+
+	if !sha256Checksum.MatchString(a.SHA256) {
+		return fmt.Errorf("artifact %s has an invalid `sha256`: %q", a.URL, a.SHA256)
+	}
+
+	if len(a.RefPaths) == 0 {
+		return fmt.Errorf("artifact %s is missing `refPaths`", a.URL)
+	}
+	for _, refPath := range a.RefPaths {
+		if refPath.Path == "" || refPath.Match == "" {
+			return fmt.Errorf("artifact %s has a refPath missing `path` or `match`", a.URL)
+		}
+		if refPath.Companion != "" {
+			return fmt.Errorf("artifact %s has a refPath with a `companion`", a.URL)
+		}
+	}
+
+	return nil
+}
+
+func (a *Artifact) parseURL() (*template.Template, error) {
+	tmpl, err := template.New("url").Option("missingkey=error").Parse(a.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact url %q: %w", a.URL, err)
+	}
+
+	return tmpl, nil
+}
+
+// URLFor returns the URL of the artifact at the given version.
+func (a *Artifact) URLFor(version string) (string, error) {
+	tmpl, err := a.parseURL()
+	if err != nil {
+		return "", err
+	}
+
+	var url strings.Builder
+	if err := tmpl.Execute(&url, struct{ Version, OS, Arch string }{version, a.OS, a.Arch}); err != nil {
+		return "", fmt.Errorf("rendering artifact url %q: %w", a.URL, err)
+	}
+
+	return url.String(), nil
+}
+
+// Checksum downloads the artifact at the given version and returns its SHA-256.
+func (a *Artifact) Checksum(version string) (string, error) {
+	url, err := a.URLFor(version)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debugf("Downloading artifact %s...", url)
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("downloading artifact %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading artifact %s: %s", url, resp.Status)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", fmt.Errorf("downloading artifact %s: %w", url, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyArtifacts downloads the artifacts of the dependencies at their current
+// version, and checks them against their checksums.
+func (c *LocalClient) VerifyArtifacts(dependencyFilePath string) error {
+	externalDeps, err := FromFile(dependencyFilePath)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []string
+	for _, dep := range externalDeps.Dependencies {
+		for _, artifact := range dep.Artifacts {
+			sum, err := artifact.Checksum(dep.Version)
+			if err != nil {
+				return fmt.Errorf("dependency %s: %w", dep.Name, err)
+			}
+
+			if sum != artifact.SHA256 {
+				url, _ := artifact.URLFor(dep.Version)
+				log.Errorf("Artifact %s of dependency %s has checksum %s, expected %s", url, dep.Name, sum, artifact.SHA256)
+				mismatches = append(mismatches, url)
+			}
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("checksums of artifacts %s do not match", strings.Join(mismatches, ", "))
+	}
+
+	return nil
+}
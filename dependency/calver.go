@@ -0,0 +1,176 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// calverTokens maps the components of a [CalVer](https://calver.org/) format
+// to the sensitivity level they belong to: years are Major, months and weeks
+// are Minor, and days and micro versions are Patch.
+var calverTokens = map[string]VersionSensitivity{
+	"YYYY":  Major,
+	"YY":    Major,
+	"0Y":    Major,
+	"MAJOR": Major,
+	"MM":    Minor,
+	"0M":    Minor,
+	"WW":    Minor,
+	"0W":    Minor,
+	"MINOR": Minor,
+	"DD":    Patch,
+	"0D":    Patch,
+	"MICRO": Patch,
+}
+
+var calverTokenRegexp = regexp.MustCompile(`YYYY|YY|0Y|MAJOR|MM|0M|WW|0W|MINOR|DD|0D|MICRO`)
+
+// calverFormat is a parsed CalVer format such as YYYY.0M.MICRO.
+type calverFormat struct {
+	format  string
+	tokens  []string
+	matcher *regexp.Regexp
+}
+
+// parseCalverFormat parses a CalVer format. Components are separated by `.`,
+// `-` or `_`, and zero-padded ones (0Y, 0M, 0W, 0D) have two digits. A
+// trailing MICRO component is optional in versions, e.g. both 24.04 and
+// 24.04.1 match YY.0M.MICRO.
+func parseCalverFormat(format string) (*calverFormat, error) {
+	if format == "" {
+		return nil, errors.New("the calver scheme requires a `format`, e.g. YYYY.0M.MICRO")
+	}
+
+	locations := calverTokenRegexp.FindAllStringIndex(format, -1)
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("calver format %q has no components", format)
+	}
+
+	f := &calverFormat{format: format}
+	pattern := `^v?`
+
+	previous := 0
+	for i, location := range locations {
+		separator := format[previous:location[0]]
+		if strings.Trim(separator, ".-_") != "" || (i > 0 && separator == "") {
+			return nil, fmt.Errorf("calver format %q has invalid separator %q", format, separator)
+		}
+
+		token := format[location[0]:location[1]]
+		digits := `\d+`
+		if token == "YYYY" {
+			digits = `\d{4}`
+		} else if strings.HasPrefix(token, "0") {
+			digits = `\d{2}`
+		}
+
+		component := regexp.QuoteMeta(separator) + `(` + digits + `)`
+		if token == "MICRO" && i == len(locations)-1 && i > 0 {
+			component = `(?:` + component + `)?`
+		}
+
+		f.tokens = append(f.tokens, token)
+		pattern += component
+		previous = location[1]
+	}
+
+	if previous != len(format) {
+		return nil, fmt.Errorf("calver format %q has trailing characters %q", format, format[previous:])
+	}
+
+	f.matcher = regexp.MustCompile(pattern + `$`)
+
+	return f, nil
+}
+
+// parse returns the components of a version, with short years expanded to
+// full years and missing components set to zero.
+func (f *calverFormat) parse(version string) ([]int, error) {
+	matches := f.matcher.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("version %q does not match calver format %s", version, f.format)
+	}
+
+	components := make([]int, len(f.tokens))
+	for i, token := range f.tokens {
+		if matches[i+1] == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s of version %q: %w", token, version, err)
+		}
+
+		if token == "YY" || token == "0Y" {
+			value += 2000
+		}
+
+		components[i] = value
+	}
+
+	return components, nil
+}
+
+// calverCompare compares two calendar versions depending on a sensitivity
+// level, i.e. only the year matters at the Major level.
+func calverCompare(a, b Version, sensitivity VersionSensitivity) (bool, error) {
+	format, err := parseCalverFormat(a.Format)
+	if err != nil {
+		return false, err
+	}
+
+	aComponents, err := format.parse(a.Version)
+	if err != nil {
+		return false, err
+	}
+
+	bComponents, err := format.parse(b.Version)
+	if err != nil {
+		return false, err
+	}
+
+	var levels []VersionSensitivity
+	switch sensitivity {
+	case Major:
+		levels = []VersionSensitivity{Major}
+	case Minor:
+		levels = []VersionSensitivity{Major, Minor}
+	case Patch:
+		levels = []VersionSensitivity{Major, Minor, Patch}
+	default:
+		return false, fmt.Errorf("unknown version sensitivity: %s", sensitivity)
+	}
+
+	for i, token := range format.tokens {
+		if !slices.Contains(levels, calverTokens[token]) {
+			continue
+		}
+
+		if aComponents[i] != bComponents[i] {
+			return aComponents[i] > bComponents[i], nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// customScheme is a user-defined version scheme: a regular expression with
+// named capture groups, and the order in which groups are compared.
+type customScheme struct {
+	matcher *regexp.Regexp
+	order   []string
+}
+
+// parseCustomScheme compiles a custom scheme, anchored so that versions match
+// it as a whole. Without an order, groups are compared in the order they appear
+// in the pattern.
+func parseCustomScheme(pattern string, order []string) (*customScheme, error) {
+	if pattern == "" {
+		return nil, errors.New("the custom scheme requires a `format` regular expression with named groups")
+	}
+
+	// Versions must match the whole pattern
+	matcher, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("compiling custom scheme format: %w", err)
+	}
+
+	var groups []string
+	for _, name := range matcher.SubexpNames() {
+		if name != "" {
+			groups = append(groups, name)
+		}
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("custom scheme format %q has no named groups", pattern)
+	}
+
+	if len(order) == 0 {
+		order = groups
+	}
+
+	for _, name := range order {
+		if !slices.Contains(groups, name) {
+			return nil, fmt.Errorf("custom scheme order refers to unknown group %q", name)
+		}
+	}
+
+	return &customScheme{matcher: matcher, order: order}, nil
+}
+
+// parse returns the values of the ordered groups of a version.
+func (s *customScheme) parse(version string) ([]string, error) {
+	matches := s.matcher.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("version %q does not match custom scheme format %s", version, s.matcher)
+	}
+
+	values := make([]string, 0, len(s.order))
+	for _, name := range s.order {
+		values = append(values, matches[s.matcher.SubexpIndex(name)])
+	}
+
+	return values, nil
+}
+
+// customCompare compares two versions of a custom scheme depending on a
+// sensitivity level: the first group of the order is Major, the second one is
+// Minor, and the others are Patch.
+//
+// Groups are compared numerically when both values are numbers, e.g. 21 > 9,
+// and as strings otherwise.
+func customCompare(a, b Version, sensitivity VersionSensitivity) (bool, error) {
+	var order []string
+	if a.Order != nil {
+		order = *a.Order
+	}
+
+	scheme, err := parseCustomScheme(a.Format, order)
+	if err != nil {
+		return false, err
+	}
+
+	aValues, err := scheme.parse(a.Version)
+	if err != nil {
+		return false, err
+	}
+
+	bValues, err := scheme.parse(b.Version)
+	if err != nil {
+		return false, err
+	}
+
+	var compared int
+	switch sensitivity {
+	case Major:
+		compared = 1
+	case Minor:
+		compared = 2
+	case Patch:
+		compared = len(aValues)
+	default:
+		return false, fmt.Errorf("unknown version sensitivity: %s", sensitivity)
+	}
+
+	for i := 0; i < compared && i < len(aValues); i++ {
+		if aValues[i] == bValues[i] {
+			continue
+		}
+
+		aNumber, aErr := strconv.ParseUint(aValues[i], 10, 64)
+		bNumber, bErr := strconv.ParseUint(bValues[i], 10, 64)
+		if aErr == nil && bErr == nil {
+			if aNumber == bNumber {
+				continue
+			}
+			return aNumber > bNumber, nil
+		}
+
+		return aValues[i] > bValues[i], nil
+	}
+
+	return false, nil
+}
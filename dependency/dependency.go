@@ -25,8 +25,11 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/blang/semver/v4"
 	log "github.com/sirupsen/logrus"
 	"go.yaml.in/yaml/v3"
 )
@@ -54,6 +57,10 @@ type Client interface {
 
 	SetVersion(dependencyFilePath, basePath, dependency, version string) error
 
+	// VerifyArtifacts downloads the artifacts of dependencies, and checks that
+	// they match their checksums
+	VerifyArtifacts(dependencyFilePath string) error
+
 	RemoteExport(dependencyFilePath string) ([]VersionUpdate, error)
 
 	CheckUpstreamVersions(deps []*Dependency) ([]VersionUpdateInfo, error)
@@ -69,6 +76,9 @@ func (u UnsupportedError) Error() string {
 
 // Dependencies is used to deserialise the configuration file.
 type Dependencies struct {
+	// Optional: minimum age of upstream releases, for dependencies that don't set their own
+	MinReleaseAge time.Duration `yaml:"minReleaseAge,omitempty"`
+
 	Dependencies []*Dependency `yaml:"dependencies"`
 }
 
@@ -76,23 +86,182 @@ type Dependencies struct {
 type Dependency struct {
 	Name string `yaml:"name"`
 	// Version of the dependency that should be present throughout your code
-	Version string `yaml:"version"`
+	Version string `yaml:"version,omitempty"`
+	// Optional: several versions pinned at once instead of Version, e.g. for a support matrix
+	Versions []*VersionEntry `yaml:"versions,omitempty"`
+	// Optional: how `upgrade` moves Versions, patch (default) or rolling
+	VersionsMode VersionsMode `yaml:"versionsMode,omitempty"`
 	// Scheme for versioning this dependency
 	Scheme VersionScheme `yaml:"scheme"`
+	// Optional: format of versions, required by the calver scheme, e.g. YYYY.0M.MICRO,
+	// and by the custom scheme as a regular expression with named groups
+	Format string `yaml:"format,omitempty"`
+	// Optional: order in which the named groups of a custom format are compared
+	Order []string `yaml:"order,omitempty"`
 	// Optional: sensitivity, to alert e.g. on new major versions
 	Sensitivity VersionSensitivity `yaml:"sensitivity,omitempty"`
+	// Optional: policy, to separate the updates that are reported from the ones that are applied
+	Policy *UpdatePolicy `yaml:"policy,omitempty"`
 	// Optional: upstream
 	Upstream map[string]string `yaml:"upstream,omitempty"`
+	// Optional: minimum age of upstream releases, e.g. 72h, to skip releases that are too recent
+	MinReleaseAge time.Duration `yaml:"minReleaseAge,omitempty"`
+	// Optional: upstream versions to never propose, either exact versions or semver ranges
+	IgnoreVersions []string `yaml:"ignoreVersions,omitempty"`
+	// Optional: whether upstream pre-releases are considered, by default as each upstream does
+	Prereleases PrereleasesMode `yaml:"prereleases,omitempty"`
+	// Optional: hold, to keep the dependency at its current version for some time
+	Hold *Hold `yaml:"hold,omitempty"`
+	// Optional: group of dependencies sharing the same version, which are only upgraded together
+	Group string `yaml:"group,omitempty"`
+	// Optional: current values going along with the version, keyed by name, e.g. the
+	// chartVersion of a Helm chart tracked by appVersion. RefPaths can refer to them.
+	Companions map[string]string `yaml:"companions,omitempty"`
+	// Optional: files downloaded at the dependency's version, whose checksums are
+	// kept in sync with it
+	Artifacts []*Artifact `yaml:"artifacts,omitempty"`
 	// List of references to this dependency in local files
 	RefPaths []*RefPath `yaml:"refPaths"`
 }
 
+// VersionEntry is one of several versions of a dependency.
+type VersionEntry struct {
+	Version string `yaml:"version"`
+	// Optional: references to this version in local files, in addition to the
+	// dependency's refPaths which reference all of its versions (e.g. a matrix)
+	RefPaths []*RefPath `yaml:"refPaths,omitempty"`
+}
+
+// VersionsMode informs us on how to upgrade the versions of a dependency with
+// several versions.
+type VersionsMode string
+
+const (
+	// VersionsPatch bumps the patch version within each tracked minor version, default.
+	VersionsPatch VersionsMode = "patch"
+	// VersionsRolling also rolls the window of minor versions forward when a new
+	// minor version appears: the oldest one is dropped, and each version takes
+	// the minor version of the next more recent one.
+	VersionsRolling VersionsMode = "rolling"
+)
+
+// PrereleasesMode informs us on whether upstream pre-releases are considered.
+type PrereleasesMode string
+
+const (
+	// PrereleasesInclude considers upstream pre-releases.
+	PrereleasesInclude PrereleasesMode = "include"
+	// PrereleasesExclude never considers upstream pre-releases.
+	PrereleasesExclude PrereleasesMode = "exclude"
+	// PrereleasesIfCurrent considers upstream pre-releases only while the current
+	// version is itself a pre-release, e.g. on a beta channel.
+	PrereleasesIfCurrent PrereleasesMode = "only-if-current-is-prerelease"
+)
+
+// IncludesPrereleases returns whether upstream pre-releases are considered
+// for the given current version, and whether that was set explicitly.
+func (d *Dependency) IncludesPrereleases(current string) (include, set bool) {
+	switch d.Prereleases {
+	case PrereleasesInclude:
+		return true, true
+	case PrereleasesExclude:
+		return false, true
+	case PrereleasesIfCurrent:
+		version, _ := SplitDigest(current)
+		v, err := semver.ParseTolerant(version)
+		return err == nil && len(v.Pre) > 0, true
+	default:
+		return false, false
+	}
+}
+
+// Entries returns the versions of the dependency, with all the references to
+// each of them. A dependency with a single version has a single entry.
+func (d *Dependency) Entries() []*VersionEntry {
+	if len(d.Versions) == 0 {
+		return []*VersionEntry{{Version: d.Version, RefPaths: d.RefPaths}}
+	}
+
+	entries := make([]*VersionEntry, 0, len(d.Versions))
+	for _, entry := range d.Versions {
+		entries = append(entries, &VersionEntry{
+			Version:  entry.Version,
+			RefPaths: append(slices.Clone(entry.RefPaths), d.RefPaths...),
+		})
+	}
+
+	return entries
+}
+
+// VersionOf returns the given version of the dependency, in its versioning scheme.
+func (d *Dependency) VersionOf(version string) Version {
+	v := Version{
+		Version: version,
+		Scheme:  d.Scheme,
+		Format:  d.Format,
+	}
+	if len(d.Order) > 0 {
+		v.Order = &d.Order
+	}
+
+	return v
+}
+
 // RefPath represents a file to check for a reference to the version.
 type RefPath struct {
 	// Path of the file to test
 	Path string `yaml:"path"`
 	// Match expression for the line that should contain the dependency's version. Regexp is supported.
 	Match string `yaml:"match"`
+	// Optional: name of the companion value the line contains, instead of the version
+	Companion string `yaml:"companion,omitempty"`
+}
+
+// UpdatePolicy separates notification from auto-upgrade for a dependency.
+type UpdatePolicy struct {
+	// Optional: highest level of change applied by `upgrade`, e.g. patch to upgrade
+	// 1.4.0 to the newest 1.4.x even when 2.0.0 exists. Only applicable to Semver versioning.
+	AutoUpgrade VersionSensitivity `yaml:"autoUpgrade,omitempty"`
+	// Optional: level of change reported by `validate` and `export` when it goes beyond
+	// autoUpgrade, e.g. major to be told about 2.0.0. Defaults to the dependency's sensitivity.
+	Notify VersionSensitivity `yaml:"notify,omitempty"`
+}
+
+// Hold keeps a dependency at its current version until a given date.
+type Hold struct {
+	// Date after which the hold lapses, e.g. 2025-06-30
+	Until string `yaml:"until"`
+	// Optional: why the dependency is held, reported alongside it
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// Expiry returns the time at which the hold lapses. A date without a time
+// lapses at the end of that day, UTC.
+func (h *Hold) Expiry() (time.Time, error) {
+	if until, err := time.Parse(time.DateOnly, h.Until); err == nil {
+		return until.AddDate(0, 0, 1), nil
+	}
+
+	until, err := time.Parse(time.RFC3339, h.Until)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("hold `until` must be a date (YYYY-MM-DD) or an RFC 3339 timestamp: %q", h.Until)
+	}
+
+	return until, nil
+}
+
+// Active returns whether the hold is still in effect at the given time.
+func (h *Hold) Active(now time.Time) bool {
+	if h == nil {
+		return false
+	}
+
+	expiry, err := h.Expiry()
+	if err != nil {
+		return false
+	}
+
+	return now.Before(expiry)
 }
 
 // UnmarshalYAML implements custom unmarshalling of Dependency with validation.
@@ -111,10 +280,17 @@ func (decoded *Dependency) UnmarshalYAML(unmarshal func(interface{}) error) erro
 		return fmt.Errorf("dependency has no `name`: %#v", d)
 	}
 
-	if d.Version == "" {
+	if d.Version == "" && len(d.Versions) == 0 {
 		return fmt.Errorf("dependency has no `version`: %#v", d)
 	}
 
+	// Validate Versions
+	if len(d.Versions) > 0 {
+		if err := (*Dependency)(d).validateVersions(); err != nil {
+			return fmt.Errorf("dependency %s is invalid: %w", d.Name, err)
+		}
+	}
+
 	// Default scheme to Semver if unset
 	if d.Scheme == "" {
 		d.Scheme = Semver
@@ -124,18 +300,101 @@ func (decoded *Dependency) UnmarshalYAML(unmarshal func(interface{}) error) erro
 	switch d.Scheme {
 	case Semver, Alpha, Random:
 		// All good!
+	case Calver:
+		format, err := parseCalverFormat(d.Format)
+		if err != nil {
+			return fmt.Errorf("dependency %s is invalid: %w", d.Name, err)
+		}
+		if _, err := format.parse(d.Version); err != nil {
+			return fmt.Errorf("dependency %s is invalid: %w", d.Name, err)
+		}
+	case Custom:
+		scheme, err := parseCustomScheme(d.Format, d.Order)
+		if err != nil {
+			return fmt.Errorf("dependency %s is invalid: %w", d.Name, err)
+		}
+		if _, err := scheme.parse(d.Version); err != nil {
+			return fmt.Errorf("dependency %s is invalid: %w", d.Name, err)
+		}
 	default:
 		return fmt.Errorf("unknown version scheme: %s", d.Scheme)
 	}
 
+	if len(d.Order) > 0 && d.Scheme != Custom {
+		return fmt.Errorf("dependency %s is invalid: `order` is only supported for the %s scheme", d.Name, Custom)
+	}
+
+	if (d.Scheme == Calver || d.Scheme == Custom) && d.Upstream["constraints"] != "" {
+		return fmt.Errorf("dependency %s is invalid: semver `constraints` don't apply to the %s scheme", d.Name, d.Scheme)
+	}
+
+	if d.Upstream["flavour"] == "container" && d.Upstream["tag"] != "" && d.Scheme != Random {
+		return fmt.Errorf("dependency %s is invalid: a floating container `tag` is tracked by digest, which requires the %s scheme", d.Name, Random)
+	}
+
+	// Validate Policy
+	if d.Policy != nil {
+		if err := d.Policy.AutoUpgrade.validate(); err != nil {
+			return fmt.Errorf("dependency %s has an invalid policy: %w", d.Name, err)
+		}
+		if err := d.Policy.Notify.validate(); err != nil {
+			return fmt.Errorf("dependency %s has an invalid policy: %w", d.Name, err)
+		}
+		if d.Policy.AutoUpgrade != "" && d.Scheme != Semver {
+			return fmt.Errorf("dependency %s is invalid: policy `autoUpgrade` is only supported for the %s scheme", d.Name, Semver)
+		}
+	}
+
+	if d.MinReleaseAge < 0 {
+		return fmt.Errorf("dependency %s is invalid: `minReleaseAge` cannot be negative", d.Name)
+	}
+
+	for _, ignored := range d.IgnoreVersions {
+		if strings.TrimSpace(ignored) == "" {
+			return fmt.Errorf("dependency %s is invalid: `ignoreVersions` cannot contain empty entries", d.Name)
+		}
+	}
+
+	switch d.Prereleases {
+	case "", PrereleasesInclude, PrereleasesExclude, PrereleasesIfCurrent:
+	default:
+		return fmt.Errorf("dependency %s is invalid: unknown prereleases mode: %s", d.Name, d.Prereleases)
+	}
+
+	if d.Hold != nil {
+		if _, err := d.Hold.Expiry(); err != nil {
+			return fmt.Errorf("dependency %s is invalid: %w", d.Name, err)
+		}
+	}
+
 	// Validate RefPaths
-	for _, refPath := range d.RefPaths {
+	refPaths := slices.Clone(d.RefPaths)
+	for _, entry := range d.Versions {
+		refPaths = append(refPaths, entry.RefPaths...)
+	}
+	for _, refPath := range refPaths {
 		if refPath.Path == "" {
 			return fmt.Errorf("dependency %s is invalid: refPath is missing `path`", d.Name)
 		}
 		if refPath.Match == "" {
 			return fmt.Errorf("dependency %s is invalid: refPath is missing `match`", d.Name)
 		}
+		if _, ok := d.Companions[refPath.Companion]; refPath.Companion != "" && !ok {
+			return fmt.Errorf("dependency %s is invalid: refPath refers to unknown companion %s", d.Name, refPath.Companion)
+		}
+	}
+
+	if len(d.Companions) > 0 && len(d.Versions) > 0 {
+		return fmt.Errorf("dependency %s is invalid: `companions` are not supported with `versions`", d.Name)
+	}
+
+	for _, artifact := range d.Artifacts {
+		if err := artifact.validate(); err != nil {
+			return fmt.Errorf("dependency %s is invalid: %w", d.Name, err)
+		}
+	}
+	if len(d.Artifacts) > 0 && len(d.Versions) > 0 {
+		return fmt.Errorf("dependency %s is invalid: `artifacts` are not supported with `versions`", d.Name)
 	}
 
 	log.Debugf("Deserialised Dependency %s: %#v", d.Name, d)
@@ -143,6 +402,44 @@ func (decoded *Dependency) UnmarshalYAML(unmarshal func(interface{}) error) erro
 	return nil
 }
 
+// validateVersions validates the settings of a dependency with several versions.
+func (d *Dependency) validateVersions() error {
+	if d.Version != "" {
+		return errors.New("`version` and `versions` are mutually exclusive")
+	}
+
+	if d.Scheme != "" && d.Scheme != Semver {
+		return fmt.Errorf("`versions` are only supported for the %s scheme", Semver)
+	}
+
+	if d.Policy != nil || d.Group != "" {
+		return errors.New("`versions` cannot be combined with `policy` or `group`")
+	}
+
+	switch d.VersionsMode {
+	case "", VersionsPatch, VersionsRolling:
+	default:
+		return fmt.Errorf("unknown versions mode: %s", d.VersionsMode)
+	}
+
+	minors := map[string]bool{}
+	for _, entry := range d.Versions {
+		version, _ := SplitDigest(entry.Version)
+		v, err := semver.ParseTolerant(version)
+		if err != nil {
+			return fmt.Errorf("version %q of `versions` is not semver: %w", entry.Version, err)
+		}
+
+		minor := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+		if minors[minor] {
+			return fmt.Errorf("`versions` has several entries for minor version %s", minor)
+		}
+		minors[minor] = true
+	}
+
+	return nil
+}
+
 func FromFile(dependencyFilePath string) (*Dependencies, error) {
 	depFile, err := os.ReadFile(dependencyFilePath)
 	if err != nil {
@@ -165,6 +462,10 @@ func FromFile(dependencyFilePath string) (*Dependencies, error) {
 		return nil, err
 	}
 
+	if dependencies.MinReleaseAge < 0 {
+		return nil, errors.New("`minReleaseAge` cannot be negative")
+	}
+
 	return dependencies, nil
 }
 
@@ -203,83 +504,144 @@ func (c *LocalClient) LocalCheck(dependencyFilePath, basePath string) error {
 		return err
 	}
 
-	var nonMatchingPaths []string
+	if err := checkGroups(dependencyFilePath, externalDeps.Dependencies); err != nil {
+		return err
+	}
+
 	for _, dep := range externalDeps.Dependencies {
 		log.Debugf("Examining dependency: %s", dep.Name)
 
-		for _, refPath := range dep.RefPaths {
-			filePath := filepath.Join(basePath, refPath.Path)
-
-			log.Debugf("Examining file: %s", filePath)
-
-			file, err := os.Open(filePath)
-			if err != nil {
+		for _, entry := range dep.Entries() {
+			if err := checkVersionEntry(dependencyFilePath, basePath, dep.Name, entry, dep.Companions); err != nil {
 				return err
 			}
+		}
 
-			match := refPath.Match
-			matcher, err := regexp.Compile(match)
-			if err != nil {
-				return fmt.Errorf("compiling regex: %w", err)
+		// The checksums of artifacts are checked like versions
+		for _, artifact := range dep.Artifacts {
+			entry := &VersionEntry{Version: artifact.SHA256, RefPaths: artifact.RefPaths}
+			if err := checkVersionEntry(dependencyFilePath, basePath, dep.Name, entry, nil); err != nil {
+				return err
 			}
-			scanner := bufio.NewScanner(file)
+		}
+	}
 
-			var found bool
-			var wrongVersion bool
+	return nil
+}
+
+// checkVersionEntry checks that the files referenced by a version of a
+// dependency contain that version, or the companion value they refer to.
+func checkVersionEntry(dependencyFilePath, basePath, name string, entry *VersionEntry, companions map[string]string) error {
+	var nonMatchingPaths []string
+	for _, refPath := range entry.RefPaths {
+		filePath := filepath.Join(basePath, refPath.Path)
+
+		version := entry.Version
+		if refPath.Companion != "" {
+			version = companions[refPath.Companion]
+		}
+
+		log.Debugf("Examining file: %s", filePath)
 
-			var lineNumber int
-			for scanner.Scan() {
-				lineNumber++
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
 
-				line := scanner.Text()
-				if matcher.MatchString(line) {
-					found = true
+		match := refPath.Match
+		matcher, err := regexp.Compile(match)
+		if err != nil {
+			return fmt.Errorf("compiling regex: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+
+		var found bool
+		var wrongVersion bool
+
+		var lineNumber int
+		for scanner.Scan() {
+			lineNumber++
+
+			line := scanner.Text()
+			if matcher.MatchString(line) {
+				found = true
+				log.Debugf(
+					"Line %d matches expected regexp %q",
+					lineNumber,
+					match,
+				)
+
+				if strings.Contains(line, version) {
 					log.Debugf(
-						"Line %d matches expected regexp %q",
+						"Line %d matches expected regexp %q and version %q: %s",
 						lineNumber,
 						match,
+						version,
+						line,
 					)
-
-					if strings.Contains(line, dep.Version) {
-						log.Debugf(
-							"Line %d matches expected regexp %q and version %q: %s",
-							lineNumber,
-							match,
-							dep.Version,
-							line,
-						)
-					} else {
-						log.Warnf(
-							"Line %d matches expected regexp %q but version %q is not present: %s",
-							lineNumber,
-							match,
-							dep.Version,
-							line,
-						)
-						wrongVersion = true
-					}
+				} else {
+					log.Warnf(
+						"Line %d matches expected regexp %q but version %q is not present: %s",
+						lineNumber,
+						match,
+						version,
+						line,
+					)
+					wrongVersion = true
 				}
 			}
+		}
 
-			if !found {
-				log.Debugf("No match found in file %s", filePath)
-				nonMatchingPaths = append(nonMatchingPaths, refPath.Path)
-			} else if wrongVersion {
-				log.Debugf("Wrong version found in file %s", filePath)
-				nonMatchingPaths = append(nonMatchingPaths, refPath.Path)
-			}
+		if !found {
+			log.Debugf("No match found in file %s", filePath)
+			nonMatchingPaths = append(nonMatchingPaths, refPath.Path)
+		} else if wrongVersion {
+			log.Debugf("Wrong version found in file %s", filePath)
+			nonMatchingPaths = append(nonMatchingPaths, refPath.Path)
+		}
+	}
+
+	if len(nonMatchingPaths) > 0 {
+		log.Errorf(
+			"%s indicates that %s should be at version %s, but the following files didn't match: %s",
+			dependencyFilePath,
+			name,
+			entry.Version,
+			strings.Join(nonMatchingPaths, ", "),
+		)
+
+		return errors.New("Dependencies are not in sync")
+	}
+
+	return nil
+}
+
+// checkGroups checks that the dependencies of each group are at the same version.
+func checkGroups(dependencyFilePath string, dependencies []*Dependency) error {
+	groupVersions := map[string]*Dependency{}
+	for _, dep := range dependencies {
+		if dep.Group == "" {
+			continue
 		}
 
-		if len(nonMatchingPaths) > 0 {
+		first, ok := groupVersions[dep.Group]
+		if !ok {
+			groupVersions[dep.Group] = dep
+			continue
+		}
+
+		if strings.TrimPrefix(dep.Version, "v") != strings.TrimPrefix(first.Version, "v") {
 			log.Errorf(
-				"%s indicates that %s should be at version %s, but the following files didn't match: %s",
+				"%s indicates that %s and %s belong to group %s, but they are at different versions: %s and %s",
 				dependencyFilePath,
+				first.Name,
 				dep.Name,
+				dep.Group,
+				first.Version,
 				dep.Version,
-				strings.Join(nonMatchingPaths, ", "),
 			)
 
-			return errors.New("Dependencies are not in sync")
+			return errors.New("Dependency groups are not consistent")
 		}
 	}
 
@@ -300,16 +662,14 @@ func (c *LocalClient) SetVersion(dependencyFilePath, basePath, dependency, versi
 		if dep.Name == dependency {
 			found = true
 
+			if len(dep.Versions) > 0 {
+				return fmt.Errorf("dependency %s has several versions, which cannot be set at once", dependency)
+			}
+
 			if err := upgradeDependency(basePath, dep, &VersionUpdateInfo{
-				Name: dep.Name,
-				Current: Version{
-					Version: dep.Version,
-					Scheme:  dep.Scheme,
-				},
-				Latest: Version{
-					Version: version,
-					Scheme:  dep.Scheme,
-				},
+				Name:            dep.Name,
+				Current:         dep.VersionOf(dep.Version),
+				Latest:          dep.VersionOf(version),
 				UpdateAvailable: true,
 			}); err != nil {
 				return err
@@ -17,9 +17,16 @@ limitations under the License.
 package dependency
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.yaml.in/yaml/v3"
@@ -122,11 +129,42 @@ func TestFileDoesntExist(t *testing.T) {
 }
 
 func TestDeserialising(t *testing.T) {
+	checksum := strings.Repeat("a", 64)
+
 	invalidYamls := []string{
 		"a b c",
 		"name:",
 		"name: test",
 		"version: 1.0.0",
+		"name: test\nversion: 1.0.0\npolicy:\n  autoUpgrade: foo",
+		"name: test\nversion: 1.0.0\npolicy:\n  notify: foo",
+		"name: test\nversion: abc\nscheme: alpha\npolicy:\n  autoUpgrade: patch",
+		"name: test\nversion: 1.0.0\nignoreVersions:\n- \"\"",
+		"name: test\nversion: 1.0.0\nhold:\n  reason: no date",
+		"name: test\nversion: 1.0.0\nhold:\n  until: next week",
+		"name: test\nversion: 2024.10.1\nscheme: calver",
+		"name: test\nversion: 24.04\nscheme: calver\nformat: YYYY.0M",
+		"name: test\nversion: 24.04\nscheme: calver\nformat: YY.0M\nupstream:\n  flavour: dummy\n  constraints: < 25.0.0",
+		"name: test\nversion: r123\nscheme: custom",
+		"name: test\nversion: r123\nscheme: custom\nformat: ^v(?P<revision>\\d+)$",
+		"name: test\nversion: r123\nscheme: custom\nformat: (?P<revision>\\d+)",
+		"name: test\nversion: r123\nscheme: custom\nformat: r(?P<revision>\\d+)\nupstream:\n  flavour: dummy\n  constraints: < 200",
+		"name: test\nversion: 1.0.0\norder:\n- revision",
+		"name: test\nversion: sha256:abc\nupstream:\n  flavour: container\n  registry: example.com/app\n  tag: stable",
+		"name: test\nversion: 1.0.0\nversions:\n- version: 1.1.0",
+		"name: test\nversions:\n- version: abc",
+		"name: test\nversions:\n- version: 1.1.0\n- version: 1.1.2",
+		"name: test\nversions:\n- version: 1.1.0\nversionsMode: foo",
+		"name: test\nversions:\n- version: 1.1.0\ngroup: foo",
+		"name: test\nversion: 1.0.0\nprereleases: always",
+		"name: test\nversions:\n- version: 1.1.0\n  refPaths:\n  - path: foo",
+		"name: test\nversion: 1.0.0\nrefPaths:\n- path: foo\n  match: bar\n  companion: chartVersion",
+		"name: test\nversions:\n- version: 1.1.0\ncompanions:\n  chartVersion: 2.0.0",
+		"name: test\nversion: 1.0.0\nartifacts:\n- sha256: " + checksum + "\n  refPaths:\n  - path: foo\n    match: bar",
+		"name: test\nversion: 1.0.0\nartifacts:\n- url: https://example.com/{{ .Version }\n  sha256: " + checksum + "\n  refPaths:\n  - path: foo\n    match: bar",
+		"name: test\nversion: 1.0.0\nartifacts:\n- url: https://example.com/{{ .Version }}\n  sha256: abc\n  refPaths:\n  - path: foo\n    match: bar",
+		"name: test\nversion: 1.0.0\nartifacts:\n- url: https://example.com/{{ .Version }}\n  sha256: " + checksum + "",
+		"name: test\nversions:\n- version: 1.1.0\nartifacts:\n- url: https://example.com/{{ .Version }}\n  sha256: " + checksum + "\n  refPaths:\n  - path: foo\n    match: bar",
 	}
 
 	for _, invalid := range invalidYamls {
@@ -139,6 +177,17 @@ func TestDeserialising(t *testing.T) {
 	validYamls := []string{
 		"name: test\nversion: 1.0.0",
 		"name: test\nversion: 100",
+		"name: test\nversion: 1.0.0\npolicy:\n  autoUpgrade: patch\n  notify: major",
+		"name: test\nversion: 1.0.0\nignoreVersions:\n- 1.2.3\n- \">=2.0.0 <2.1.0\"",
+		"name: test\nversion: 1.0.0\nhold:\n  until: 2025-06-30\n  reason: waiting for the fix",
+		"name: test\nversion: 1.0.0\nhold:\n  until: 2025-06-30T12:00:00Z",
+		"name: test\nversion: 1.0.0-rc.1\nprereleases: only-if-current-is-prerelease",
+		"name: test\nversion: 24.04\nscheme: calver\nformat: YY.0M.MICRO",
+		"name: test\nversion: r123\nscheme: custom\nformat: ^r(?P<revision>\\d+)$\norder:\n- revision",
+		"name: test\nversion: sha256:abc\nscheme: random\nupstream:\n  flavour: container\n  registry: example.com/app\n  tag: stable",
+		"name: test\nversions:\n- version: 1.30.6\n- version: 1.31.2\n  refPaths:\n  - path: foo\n    match: bar\nversionsMode: rolling",
+		"name: test\nversion: 1.0.0\ncompanions:\n  chartVersion: 2.0.0\nrefPaths:\n- path: foo\n  match: bar\n  companion: chartVersion",
+		"name: test\nversion: 1.0.0\nartifacts:\n- os: linux\n  arch: amd64\n  url: https://example.com/{{ .Version }}/{{ .OS }}-{{ .Arch }}\n  sha256: " + checksum + "\n  refPaths:\n  - path: foo\n    match: bar",
 	}
 
 	for _, valid := range validYamls {
@@ -149,6 +198,59 @@ func TestDeserialising(t *testing.T) {
 	}
 }
 
+func TestHoldActive(t *testing.T) {
+	hold := &Hold{Until: "2025-06-30"}
+
+	require.True(t, hold.Active(time.Date(2025, 6, 30, 23, 59, 0, 0, time.UTC)))
+	require.False(t, hold.Active(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)))
+
+	hold = &Hold{Until: "2025-06-30T12:00:00Z"}
+
+	require.True(t, hold.Active(time.Date(2025, 6, 30, 11, 0, 0, 0, time.UTC)))
+	require.False(t, hold.Active(time.Date(2025, 6, 30, 13, 0, 0, 0, time.UTC)))
+
+	var noHold *Hold
+	require.False(t, noHold.Active(time.Now()))
+}
+
+func TestLocalGroups(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte("KUBECTL: 1.31.2\nKUBELET: 1.31.1"), 0o644)
+	require.NoError(t, err)
+
+	dependencies := `
+dependencies:
+  - name: kubectl
+    version: 1.31.2
+    group: kubernetes
+    refPaths:
+    - path: test.txt
+      match: KUBECTL
+  - name: kubelet
+    version: %s
+    group: kubernetes
+    refPaths:
+    - path: test.txt
+      match: KUBELET
+`
+
+	client, err := NewLocalClient()
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(fmt.Sprintf(dependencies, "1.31.1")), 0o644)
+	require.NoError(t, err)
+	err = client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.EqualError(t, err, "Dependency groups are not consistent")
+
+	err = os.WriteFile(filepath.Join(dir, "test.txt"), []byte("KUBECTL: 1.31.2\nKUBELET: 1.31.2"), 0o644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(fmt.Sprintf(dependencies, "1.31.2")), 0o644)
+	require.NoError(t, err)
+	err = client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+}
+
 func TestSetVersion(t *testing.T) {
 	dir := t.TempDir()
 	testFile := filepath.Join(dir, "test.txt")
@@ -226,3 +328,156 @@ dependencies:
 	require.NoError(t, err)
 	require.Equal(t, "APP1_VERSION: 2.1.0\nAPP2_VERSION: 0.0.1\nAPP1_VERSION: 2.1.0", string(got))
 }
+
+func TestIncludesPrereleases(t *testing.T) {
+	for _, tc := range []struct {
+		mode            PrereleasesMode
+		current         string
+		expectedInclude bool
+		expectedSet     bool
+	}{
+		{mode: "", current: "1.0.0-rc.1"},
+		{mode: PrereleasesInclude, current: "1.0.0", expectedInclude: true, expectedSet: true},
+		{mode: PrereleasesExclude, current: "1.0.0-rc.1", expectedSet: true},
+		{mode: PrereleasesIfCurrent, current: "v1.0.0-beta.2", expectedInclude: true, expectedSet: true},
+		{mode: PrereleasesIfCurrent, current: "1.0.0", expectedSet: true},
+		{mode: PrereleasesIfCurrent, current: "latest", expectedSet: true},
+	} {
+		d := Dependency{Name: "test", Prereleases: tc.mode}
+
+		include, set := d.IncludesPrereleases(tc.current)
+		require.Equal(t, tc.expectedInclude, include, "%s with %s", tc.mode, tc.current)
+		require.Equal(t, tc.expectedSet, set, "%s with %s", tc.mode, tc.current)
+	}
+}
+
+func TestArtifacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, "artifact at "+req.URL.Path)
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte("artifact at /1.0.0/tool-linux-amd64"))
+	checksum := hex.EncodeToString(sum[:])
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte("VERSION: 1.0.0\nSHA256: "+checksum), 0o644)
+	require.NoError(t, err)
+
+	dependencies := `
+dependencies:
+  - name: tool
+    version: 1.0.0
+    artifacts:
+    - os: linux
+      arch: amd64
+      url: %s/{{ .Version }}/tool-{{ .OS }}-{{ .Arch }}
+      sha256: %s
+      refPaths:
+      - path: test.txt
+        match: SHA256
+    refPaths:
+    - path: test.txt
+      match: VERSION
+`
+
+	client, err := NewLocalClient()
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(fmt.Sprintf(dependencies, server.URL, checksum)), 0o644)
+	require.NoError(t, err)
+	require.NoError(t, client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir))
+	require.NoError(t, client.VerifyArtifacts(filepath.Join(dir, "dependencies.yaml")))
+
+	wrong := strings.Repeat("0", 64)
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(fmt.Sprintf(dependencies, server.URL, wrong)), 0o644)
+	require.NoError(t, err)
+	require.Error(t, client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir))
+	require.Error(t, client.VerifyArtifacts(filepath.Join(dir, "dependencies.yaml")))
+}
+
+func TestDiscoverActions(t *testing.T) {
+	dir := t.TempDir()
+	workflows := filepath.Join(dir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflows, 0o755))
+
+	sha := "11bd71901bbe5b1630ceea73d27597364c9af683"
+	err := os.WriteFile(filepath.Join(workflows, "ci.yml"), []byte(`jobs:
+  test:
+    steps:
+      - uses: actions/checkout@`+sha+` # v4.2.2
+      - uses: actions/setup-go@v5
+      - uses: ./.github/actions/local
+      - uses: docker://alpine:3.20
+      - uses: github/codeql-action/init@v3.26.6
+      - uses: github/codeql-action/analyze@v3.26.6
+      - uses: owner/unversioned@main
+      - uses: owner/uncommented@`+sha+`
+`), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(workflows, "release.yaml"), []byte(`jobs:
+  release:
+    uses: owner/workflows/.github/workflows/release.yml@v1.2
+    steps:
+      - uses: "actions/checkout@`+sha+`" # v4.2.2
+      - uses: owner/mixed@v1.0.0
+`), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(workflows, "other.yml"), []byte("    - uses: owner/mixed@v2.0.0\n"), 0o644)
+	require.NoError(t, err)
+
+	deps, err := DiscoverActions(dir, ".github")
+	require.NoError(t, err)
+
+	ci := filepath.Join(".github", "workflows", "ci.yml")
+	release := filepath.Join(".github", "workflows", "release.yaml")
+	require.Equal(t, []*Dependency{
+		{
+			Name:    "actions/checkout",
+			Version: "v4.2.2",
+			Scheme:  Semver,
+			Upstream: map[string]string{
+				"flavour":   "github",
+				"url":       "actions/checkout",
+				"pinCommit": "true",
+			},
+			Companions: map[string]string{"commit": sha},
+			RefPaths: []*RefPath{
+				{Path: ci, Match: `uses:\s*["']?actions/checkout(/[^@\s"']*)?@`},
+				{Path: ci, Match: `uses:\s*["']?actions/checkout(/[^@\s"']*)?@`, Companion: "commit"},
+				{Path: release, Match: `uses:\s*["']?actions/checkout(/[^@\s"']*)?@`},
+				{Path: release, Match: `uses:\s*["']?actions/checkout(/[^@\s"']*)?@`, Companion: "commit"},
+			},
+		},
+		{
+			Name:     "actions/setup-go",
+			Version:  "v5",
+			Scheme:   Semver,
+			Upstream: map[string]string{"flavour": "github", "url": "actions/setup-go", "versionTemplate": "v{{ .Major }}"},
+			RefPaths: []*RefPath{{Path: ci, Match: `uses:\s*["']?actions/setup-go(/[^@\s"']*)?@`}},
+		},
+		{
+			Name:     "github/codeql-action",
+			Version:  "v3.26.6",
+			Scheme:   Semver,
+			Upstream: map[string]string{"flavour": "github", "url": "github/codeql-action"},
+			RefPaths: []*RefPath{{Path: ci, Match: `uses:\s*["']?github/codeql-action(/[^@\s"']*)?@`}},
+		},
+		{
+			Name:     "owner/workflows",
+			Version:  "v1.2",
+			Scheme:   Semver,
+			Upstream: map[string]string{"flavour": "github", "url": "owner/workflows", "versionTemplate": "v{{ .Major }}.{{ .Minor }}"},
+			RefPaths: []*RefPath{{Path: release, Match: `uses:\s*["']?owner/workflows(/[^@\s"']*)?@`}},
+		},
+	}, deps.Dependencies)
+
+	// The generated dependencies are in sync with the workflows
+	require.NoError(t, ToFile(filepath.Join(dir, "dependencies.yaml"), deps))
+
+	client, err := NewLocalClient()
+	require.NoError(t, err)
+	require.NoError(t, client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir))
+}
@@ -18,6 +18,7 @@ package dependency
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/blang/semver/v4"
 	log "github.com/sirupsen/logrus"
@@ -27,6 +28,12 @@ import (
 type Version struct {
 	Version string
 	Scheme  VersionScheme
+	// Format of the version, for schemes that need one, e.g. YYYY.0M.MICRO for
+	// Calver, or a regular expression with named groups for Custom
+	Format string
+	// Order in which the named groups of a Custom format are compared. Held by
+	// pointer to keep versions comparable.
+	Order *[]string
 }
 
 // VersionScheme informs us on how to compare two versions.
@@ -39,6 +46,12 @@ const (
 	Alpha VersionScheme = "alpha"
 	// Random when releases do not support sorting (e.g. hashes).
 	Random VersionScheme = "random"
+	// Calver [Calendar versioning](https://calver.org/), following a format
+	// such as YYYY.MM.DD or YY.0M.MICRO.
+	Calver VersionScheme = "calver"
+	// Custom user-defined scheme, following a regular expression with named
+	// groups, compared in a given order.
+	Custom VersionScheme = "custom"
 )
 
 type VersionUpdateInfo struct {
@@ -46,6 +59,21 @@ type VersionUpdateInfo struct {
 	Current         Version
 	Latest          Version
 	UpdateAvailable bool
+	// Highest upstream version regardless of the dependency's update policy.
+	// Only set for dependencies with a Policy.
+	Notify          *Version
+	NotifyAvailable bool
+	// Set when the dependency is held at its current version: no update is
+	// available until the hold lapses.
+	Hold *Hold
+	// Version the dependency would have been updated to without its hold, if any
+	Held *Version
+	// Entry of the dependency's Versions this update is about, for dependencies
+	// with several versions
+	Entry *VersionEntry
+	// Companion values going along with Latest, e.g. the chartVersion of a Helm
+	// chart tracked by appVersion
+	Companions map[string]string
 }
 
 // VersionUpdate represents the schema of the output format
@@ -54,11 +82,22 @@ type VersionUpdate struct {
 	Name       string `json:"name"        yaml:"name"`
 	Version    string `json:"version"     yaml:"version"`
 	NewVersion string `json:"new_version" yaml:"new_version"`
+	// Whether NewVersion goes beyond the dependency's auto-upgrade policy,
+	// i.e. it is reported but won't be applied by `upgrade`
+	OutsidePolicy bool `json:"outside_policy,omitempty" yaml:"outside_policy,omitempty"`
+	// Set when the dependency is held at Version, i.e. NewVersion won't be
+	// applied by `upgrade` before that date
+	HeldUntil  string `json:"held_until,omitempty"  yaml:"held_until,omitempty"`
+	HoldReason string `json:"hold_reason,omitempty" yaml:"hold_reason,omitempty"`
+	// Companion values going along with NewVersion
+	Companions map[string]string `json:"companions,omitempty" yaml:"companions,omitempty"`
 }
 
 // VersionSensitivity informs us on how to compare whether a version is more
 // recent than another, for example to only notify on new major versions
-// Only applicable to Semver versioning.
+// Only applicable to Semver, Calver and Custom versioning: for Calver, Major,
+// Minor and Patch map to the year, the month (or week) and the day or micro
+// version; for Custom, to the first, second and remaining groups of the order.
 type VersionSensitivity string
 
 const (
@@ -70,6 +109,17 @@ const (
 	Major VersionSensitivity = "major"
 )
 
+// validate returns an error if the sensitivity is not a known one. An empty
+// sensitivity is valid, and means the default applies.
+func (s VersionSensitivity) validate() error {
+	switch s {
+	case "", Patch, Minor, Major:
+		return nil
+	default:
+		return fmt.Errorf("unknown version sensitivity: %s", s)
+	}
+}
+
 // MoreRecentThan checks whether a given version is more recent than another one.
 //
 // If the VersionScheme is "random", then it will return true if a != b.
@@ -91,6 +141,18 @@ func (a Version) MoreSensitivelyRecentThan(b Version, sensitivity VersionSensiti
 		return false, fmt.Errorf("trying to compare incompatible 'Version' schemes: %s and %s", a.Scheme, b.Scheme)
 	}
 
+	// Versions pinned by digest compare by version, unless the same version was
+	// pushed again with another digest
+	if a.Scheme != Random {
+		var aDigest, bDigest string
+		a.Version, aDigest = SplitDigest(a.Version)
+		b.Version, bDigest = SplitDigest(b.Version)
+
+		if a.Version == b.Version && aDigest != "" && aDigest != bDigest && sensitivity == Patch {
+			return true, nil
+		}
+	}
+
 	switch a.Scheme {
 	case Semver:
 		aSemver, err := semver.ParseTolerant(a.Version)
@@ -106,6 +168,10 @@ func (a Version) MoreSensitivelyRecentThan(b Version, sensitivity VersionSensiti
 		}
 
 		return semverCompare(aSemver, bSemver, sensitivity)
+	case Calver:
+		return calverCompare(a, b, sensitivity)
+	case Custom:
+		return customCompare(a, b, sensitivity)
 	case Alpha:
 		// Alphanumeric comparison (basic string compare)
 		return a.Version > b.Version, nil
@@ -117,6 +183,17 @@ func (a Version) MoreSensitivelyRecentThan(b Version, sensitivity VersionSensiti
 	}
 }
 
+// SplitDigest splits a version pinned by digest, e.g. 1.2.3@sha256:..., into
+// its version and digest. The digest is empty for other versions.
+func SplitDigest(version string) (string, string) {
+	i := strings.LastIndex(version, "@")
+	if i < 0 || !strings.Contains(version[i+1:], ":") {
+		return version, ""
+	}
+
+	return version[:i], version[i+1:]
+}
+
 // semverCompare compares two semver versions depending on a sensitivity level.
 func semverCompare(a, b semver.Version, sensitivity VersionSensitivity) (bool, error) {
 	switch sensitivity {
@@ -130,3 +207,24 @@ func semverCompare(a, b semver.Version, sensitivity VersionSensitivity) (bool, e
 		return false, fmt.Errorf("unknown version sensitivity: %s", sensitivity)
 	}
 }
+
+// WithinSensitivity checks whether a given version is at most a change of the
+// given sensitivity away from another one, e.g. 1.4.3 and 1.3.0 are within a
+// Patch change of 1.4.0, but 1.5.0 isn't.
+func (a Version) WithinSensitivity(b Version, sensitivity VersionSensitivity) (bool, error) {
+	var moreRecent bool
+	var err error
+
+	switch sensitivity {
+	case Major:
+		return true, nil
+	case Minor:
+		moreRecent, err = a.MoreSensitivelyRecentThan(b, Major)
+	case Patch, "":
+		moreRecent, err = a.MoreSensitivelyRecentThan(b, Minor)
+	default:
+		return false, fmt.Errorf("unknown version sensitivity: %s", sensitivity)
+	}
+
+	return !moreRecent, err
+}
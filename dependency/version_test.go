@@ -29,34 +29,34 @@ import (
 func TestSanity(t *testing.T) {
 	var err error
 
-	a := Version{"1.0.0", Semver}
-	b := Version{"2.0.0", Alpha}
+	a := Version{Version: "1.0.0", Scheme: Semver}
+	b := Version{Version: "2.0.0", Scheme: Alpha}
 
 	_, err = a.MoreRecentThan(b)
 	require.Error(t, err)
 
-	a = Version{"1.0.0", "Foo"}
-	b = Version{"2.0.0", "Foo"}
+	a = Version{Version: "1.0.0", Scheme: "Foo"}
+	b = Version{Version: "2.0.0", Scheme: "Foo"}
 
 	_, err = a.MoreRecentThan(b)
 	require.Error(t, err)
 
-	a = Version{"ami-1234", Semver}
-	b = Version{"ami-4567", Semver}
+	a = Version{Version: "ami-1234", Scheme: Semver}
+	b = Version{Version: "ami-4567", Scheme: Semver}
 
 	_, err = a.MoreRecentThan(b)
 	require.Error(t, err)
 
-	a = Version{"1.0.0", Semver}
-	b = Version{"bad-version", Semver}
+	a = Version{Version: "1.0.0", Scheme: Semver}
+	b = Version{Version: "bad-version", Scheme: Semver}
 
 	_, err = a.MoreRecentThan(b)
 	require.Error(t, err)
 }
 
 func TestSemverVersions(t *testing.T) {
-	a := Version{"1.0.0", Semver}
-	b := Version{"2.0.0", Semver}
+	a := Version{Version: "1.0.0", Scheme: Semver}
+	b := Version{Version: "2.0.0", Scheme: Semver}
 
 	//nolint: errcheck
 	shouldBeFalse, _ := a.MoreRecentThan(b)
@@ -72,8 +72,8 @@ func TestSemverVersions(t *testing.T) {
 }
 
 func TestSemverSensitiveVersions(t *testing.T) {
-	a := Version{"1.0.0", Semver}
-	b := Version{"1.1.0", Semver}
+	a := Version{Version: "1.0.0", Scheme: Semver}
+	b := Version{Version: "1.1.0", Scheme: Semver}
 
 	//nolint: errcheck
 	shouldBeFalse, _ := b.MoreSensitivelyRecentThan(a, Major)
@@ -87,8 +87,8 @@ func TestSemverSensitiveVersions(t *testing.T) {
 	shouldBeTrue, _ = b.MoreSensitivelyRecentThan(a, Patch)
 	require.True(t, shouldBeTrue)
 
-	a = Version{"1.0.0", Semver}
-	b = Version{"1.0.1", Semver}
+	a = Version{Version: "1.0.0", Scheme: Semver}
+	b = Version{Version: "1.0.1", Scheme: Semver}
 
 	//nolint: errcheck
 	shouldBeFalse, _ = b.MoreSensitivelyRecentThan(a, Major)
@@ -105,8 +105,8 @@ func TestSemverSensitiveVersions(t *testing.T) {
 	_, shouldError := b.MoreSensitivelyRecentThan(a, "foo")
 	require.Error(t, shouldError)
 
-	a = Version{"6.21.0", Semver}
-	b = Version{"8.1.8", Semver}
+	a = Version{Version: "6.21.0", Scheme: Semver}
+	b = Version{Version: "8.1.8", Scheme: Semver}
 
 	//nolint: errcheck
 	shouldBeTrue, _ = b.MoreSensitivelyRecentThan(a, Minor)
@@ -114,8 +114,8 @@ func TestSemverSensitiveVersions(t *testing.T) {
 }
 
 func TestAlphaVersions(t *testing.T) {
-	a := Version{"20180101-commitid", Alpha}
-	b := Version{"20180505-commitid", Alpha}
+	a := Version{Version: "20180101-commitid", Scheme: Alpha}
+	b := Version{Version: "20180505-commitid", Scheme: Alpha}
 
 	//nolint: errcheck
 	shouldBeFalse, _ := a.MoreRecentThan(b)
@@ -131,8 +131,8 @@ func TestAlphaVersions(t *testing.T) {
 }
 
 func TestRandomVersions(t *testing.T) {
-	a := Version{"ami-09bbefc07310f7914", Random}
-	b := Version{"ami-0199284372364b02a", Random}
+	a := Version{Version: "ami-09bbefc07310f7914", Scheme: Random}
+	b := Version{Version: "ami-0199284372364b02a", Scheme: Random}
 
 	//nolint: errcheck
 	shouldBeTrue, _ := b.MoreRecentThan(a)
@@ -142,3 +142,165 @@ func TestRandomVersions(t *testing.T) {
 	shouldBeFalse, _ := a.MoreRecentThan(a)
 	require.False(t, shouldBeFalse)
 }
+
+func TestWithinSensitivity(t *testing.T) {
+	current := Version{Version: "1.4.0", Scheme: Semver}
+
+	for _, tc := range []struct {
+		version     string
+		sensitivity VersionSensitivity
+		expected    bool
+	}{
+		{"1.4.3", Patch, true},
+		{"1.3.0", Patch, true},
+		{"1.5.0", Patch, false},
+		{"1.5.0", Minor, true},
+		{"2.0.0", Minor, false},
+		{"2.0.0", Major, true},
+	} {
+		within, err := Version{Version: tc.version, Scheme: Semver}.WithinSensitivity(current, tc.sensitivity)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, within, "%s within %s of %s", tc.version, tc.sensitivity, current.Version)
+	}
+
+	_, err := Version{Version: "1.5.0", Scheme: Semver}.WithinSensitivity(current, "foo")
+	require.Error(t, err)
+}
+
+func TestCalverVersions(t *testing.T) {
+	for _, tc := range []struct {
+		format      string
+		a, b        string
+		sensitivity VersionSensitivity
+		expected    bool
+	}{
+		{"YYYY.MM.DD", "2024.10.1", "2024.9.30", Patch, true},
+		{"YYYY.MM.DD", "2024.10.1", "2024.9.30", Minor, true},
+		{"YYYY.MM.DD", "2024.10.1", "2024.9.30", Major, false},
+		{"YYYY.MM.DD", "2025.1.1", "2024.12.31", Major, true},
+		{"YYYY.MM.DD", "2024.9.30", "2024.10.1", Patch, false},
+		{"YYYY.MM.DD", "2024.10.1", "2024.10.1", Patch, false},
+		{"YY.0M.MICRO", "24.04.1", "24.04", Patch, true},
+		{"YY.0M.MICRO", "24.04.1", "24.04", Minor, false},
+		{"YY.0M.MICRO", "24.10", "24.04.3", Minor, true},
+		{"YY.0M.MICRO", "v25.04", "v24.10", Major, true},
+		{"YYYY.0M.MICRO", "2024.10.2", "2024.10.10", Patch, false},
+		{"YYYY-0M-0D", "2024-10-02", "2024-09-30", Patch, true},
+	} {
+		a := Version{Version: tc.a, Scheme: Calver, Format: tc.format}
+		b := Version{Version: tc.b, Scheme: Calver, Format: tc.format}
+
+		moreRecent, err := a.MoreSensitivelyRecentThan(b, tc.sensitivity)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, moreRecent, "%s more recent than %s (%s)", tc.a, tc.b, tc.sensitivity)
+	}
+
+	for _, tc := range []struct {
+		format string
+		a, b   string
+	}{
+		{"", "2024.10.1", "2024.9.30"},
+		{"YYYY/MM", "2024/10", "2024/09"},
+		{"YYYY.MM.DDx", "2024.10.1", "2024.9.30"},
+		{"YYYY.MM.DD", "2024.10", "2024.9.30"},
+		{"YYYY.MM.DD", "1.2.3-beta", "2024.9.30"},
+	} {
+		a := Version{Version: tc.a, Scheme: Calver, Format: tc.format}
+		b := Version{Version: tc.b, Scheme: Calver, Format: tc.format}
+
+		_, err := a.MoreRecentThan(b)
+		require.Error(t, err, "format %q comparing %s and %s", tc.format, tc.a, tc.b)
+	}
+}
+
+func TestCustomVersions(t *testing.T) {
+	jdk := `^jdk-(?P<feature>\d+)\.(?P<interim>\d+)\.(?P<update>\d+)\+(?P<build>\d+)$`
+	eks := `^v?(?P<major>\d+)\.(?P<minor>\d+)\.(?P<patch>\d+)-build\.(?P<build>\d+)-eksbuild\.(?P<eksbuild>\d+)$`
+
+	for _, tc := range []struct {
+		format      string
+		order       []string
+		a, b        string
+		sensitivity VersionSensitivity
+		expected    bool
+	}{
+		{`^r(?P<revision>\d+)$`, nil, "r123", "r99", Patch, true},
+		{`^r(?P<revision>\d+)$`, nil, "r99", "r123", Patch, false},
+		{`^r(?P<revision>\d+)$`, nil, "r123", "r123", Patch, false},
+		{jdk, nil, "jdk-21.0.2+13", "jdk-21.0.2+9", Patch, true},
+		{jdk, nil, "jdk-21.0.2+13", "jdk-21.0.2+9", Minor, false},
+		{jdk, nil, "jdk-22.0.0+1", "jdk-21.0.2+13", Major, true},
+		{eks, nil, "2.3.1-build.45-eksbuild.2", "2.3.1-build.45-eksbuild.10", Patch, false},
+		{eks, nil, "2.3.1-build.46-eksbuild.1", "2.3.1-build.45-eksbuild.10", Patch, true},
+		{eks, []string{"eksbuild", "build"}, "2.3.1-build.45-eksbuild.2", "2.3.1-build.46-eksbuild.1", Major, true},
+		{`^(?P<codename>[a-z]+)-(?P<date>\d{8})$`, nil, "noble-20240801", "jammy-20240901", Major, true},
+	} {
+		a := Version{Version: tc.a, Scheme: Custom, Format: tc.format, Order: &tc.order}
+		b := Version{Version: tc.b, Scheme: Custom, Format: tc.format, Order: &tc.order}
+
+		moreRecent, err := a.MoreSensitivelyRecentThan(b, tc.sensitivity)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, moreRecent, "%s more recent than %s (%s)", tc.a, tc.b, tc.sensitivity)
+	}
+
+	for _, tc := range []struct {
+		format string
+		order  []string
+		a, b   string
+	}{
+		{"", nil, "r1", "r2"},
+		{`^r\d+$`, nil, "r1", "r2"},
+		{`^r(?P<revision>\d+`, nil, "r1", "r2"},
+		{`^r(?P<revision>\d+)$`, []string{"build"}, "r1", "r2"},
+		{`^r(?P<revision>\d+)$`, nil, "r1", "v2"},
+		{`r(?P<revision>\d+)`, nil, "r1", "release-r2"},
+	} {
+		a := Version{Version: tc.a, Scheme: Custom, Format: tc.format, Order: &tc.order}
+		b := Version{Version: tc.b, Scheme: Custom, Format: tc.format, Order: &tc.order}
+
+		_, err := a.MoreRecentThan(b)
+		require.Error(t, err, "format %q comparing %s and %s", tc.format, tc.a, tc.b)
+	}
+
+	// Versions stay comparable
+	dep := &Dependency{Scheme: Custom, Format: jdk, Order: []string{"feature", "build"}}
+	require.True(t, dep.VersionOf("jdk-21.0.2+13") == dep.VersionOf("jdk-21.0.2+13"))
+}
+
+func TestDigestVersions(t *testing.T) {
+	const (
+		digestA = "sha256:aaaa"
+		digestB = "sha256:bbbb"
+	)
+
+	for _, tc := range []struct {
+		a, b        string
+		sensitivity VersionSensitivity
+		expected    bool
+	}{
+		{"1.2.4@" + digestB, "1.2.3@" + digestA, Patch, true},
+		{"1.2.3@" + digestA, "1.2.4@" + digestB, Patch, false},
+		{"1.2.3@" + digestB, "1.2.3@" + digestA, Patch, true},
+		{"1.2.3@" + digestB, "1.2.3@" + digestA, Minor, false},
+		{"1.2.3@" + digestA, "1.2.3@" + digestA, Patch, false},
+		{"1.2.3@" + digestA, "1.2.3", Patch, true},
+		{"1.2.3", "1.2.3@" + digestA, Patch, false},
+		{"v1.3.0@" + digestA, "v1.2.3@" + digestB, Minor, true},
+	} {
+		a := Version{Version: tc.a, Scheme: Semver}
+		b := Version{Version: tc.b, Scheme: Semver}
+
+		moreRecent, err := a.MoreSensitivelyRecentThan(b, tc.sensitivity)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, moreRecent, "%s more recent than %s (%s)", tc.a, tc.b, tc.sensitivity)
+	}
+
+	for version, expected := range map[string][2]string{
+		"1.2.3@" + digestA: {"1.2.3", digestA},
+		"1.2.3":            {"1.2.3", ""},
+		"user@example":     {"user@example", ""},
+	} {
+		v, digest := SplitDigest(version)
+		require.Equal(t, expected, [2]string{v, digest}, version)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	log "github.com/sirupsen/logrus"
+	"go.yaml.in/yaml/v3"
+)
+
+// RegistryCredentialsFile is the environment variable pointing to the
+// credentials file, see LoadCredentials.
+const RegistryCredentialsFile = "REGISTRY_CREDENTIALS_FILE"
+
+// Credentials to authenticate to a registry.
+type Credentials struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password,omitempty"`
+	// Optional: environment variable holding the password, to keep it out of the file
+	PasswordEnv string `yaml:"passwordEnv,omitempty"`
+}
+
+// ResolvedPassword returns the password of the credentials, read from
+// PasswordEnv if set.
+func (c Credentials) ResolvedPassword() (string, error) {
+	if c.PasswordEnv == "" {
+		return c.Password, nil
+	}
+
+	password := os.Getenv(c.PasswordEnv)
+	if password == "" {
+		return "", fmt.Errorf("environment variable %s is not set", c.PasswordEnv)
+	}
+
+	return password, nil
+}
+
+// authenticator returns an authenticator for the credentials.
+func (c Credentials) authenticator() (authn.Authenticator, error) {
+	password, err := c.ResolvedPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	return &authn.Basic{Username: c.Username, Password: password}, nil
+}
+
+// LoadCredentials reads a credentials file: a YAML mapping of registry or Helm
+// repository hosts, e.g. ghcr.io, or of names referred to by the `auth` of
+// upstreams, to credentials.
+func LoadCredentials(path string) (map[string]Credentials, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading registry credentials: %w", err)
+	}
+	defer file.Close()
+
+	var credentials map[string]Credentials
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&credentials); err != nil {
+		return nil, fmt.Errorf("decoding registry credentials %s: %w", path, err)
+	}
+
+	for name, c := range credentials {
+		if c.Username == "" || (c.Password == "") == (c.PasswordEnv == "") {
+			return nil, fmt.Errorf("registry credentials %s need a username, and either a password or a passwordEnv", name)
+		}
+	}
+
+	return credentials, nil
+}
+
+// BasicCredentials returns the username and password of a host, e.g. of a
+// Helm repository, or of the name an upstream's `auth` refers to, from the
+// credentials file pointed to by RegistryCredentialsFile. Without credentials
+// for it, an explicit name is an error, and a host falls back to the username
+// and password of the given environment variables.
+func BasicCredentials(name string, explicit bool, usernameEnv, passwordEnv string) (username, password string, err error) {
+	var credentials map[string]Credentials
+	if path := os.Getenv(RegistryCredentialsFile); path != "" {
+		credentials, err = LoadCredentials(path)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	c, ok := credentials[name]
+	switch {
+	case ok:
+		log.Debugf("Using credentials %s", name)
+		password, err = c.ResolvedPassword()
+		if err != nil {
+			return "", "", fmt.Errorf("credentials %s: %w", name, err)
+		}
+		return c.Username, password, nil
+	case explicit:
+		return "", "", fmt.Errorf("unknown credentials %q", name)
+	default:
+		return os.Getenv(usernameEnv), os.Getenv(passwordEnv), nil
+	}
+}
+
+// credentialsKeychain resolves credentials by registry host.
+type credentialsKeychain map[string]Credentials
+
+// Resolve implements authn.Keychain.
+func (k credentialsKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	credentials, ok := k[resource.RegistryStr()]
+	if !ok {
+		return authn.Anonymous, nil
+	}
+
+	log.Debugf("Using registry credentials for %s", resource.RegistryStr())
+	return credentials.authenticator()
+}
+
+// basicKeychain resolves the same credentials for all registries.
+type basicKeychain struct {
+	auth *authn.Basic
+}
+
+// Resolve implements authn.Keychain.
+func (k basicKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return k.auth, nil
+}
+
+// ecrHost matches the hosts of AWS ECR registries, capturing the account ID and
+// the region, e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com.
+var ecrHost = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// ECRGetAuthorizationTokenAPI is the part of the ECR API used for authentication.
+type ECRGetAuthorizationTokenAPI interface {
+	GetAuthorizationToken(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error)
+}
+
+// ecrKeychain authenticates to AWS ECR registries with the AWS configuration
+// of the container client.
+type ecrKeychain struct {
+	newClient func(region string) (ECRGetAuthorizationTokenAPI, error)
+}
+
+// newECRClient returns an ECR client for the given region, configured with
+// AWSConfig, or with the standard AWS SDK configuration loaded on first use.
+func (c *Container) newECRClient(region string) (ECRGetAuthorizationTokenAPI, error) {
+	if c.AWSConfig == nil {
+		cfg, err := config.LoadDefaultConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("loading AWS config: %w", err)
+		}
+		c.AWSConfig = &cfg
+	}
+
+	return ecr.NewFromConfig(*c.AWSConfig, func(o *ecr.Options) {
+		o.Region = region
+	}), nil
+}
+
+// Resolve implements authn.Keychain. When no ECR authorization token can be
+// retrieved, it resolves to anonymous access so that the following keychains,
+// e.g. the Docker config, are still tried.
+func (k ecrKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	matches := ecrHost.FindStringSubmatch(resource.RegistryStr())
+	if matches == nil {
+		return authn.Anonymous, nil
+	}
+
+	auth, err := k.authenticator(resource.RegistryStr(), matches[2])
+	if err != nil {
+		log.Warnf("Cannot authenticate to %s with the AWS configuration: %v", resource.RegistryStr(), err)
+		return authn.Anonymous, nil
+	}
+
+	return auth, nil
+}
+
+// authenticator returns the credentials of an ECR authorization token for the
+// registry in the given region.
+func (k ecrKeychain) authenticator(registry, region string) (authn.Authenticator, error) {
+	client, err := k.newClient(region)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Retrieving ECR authorization token for %s", registry)
+	output, err := client.GetAuthorizationToken(context.Background(), &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return nil, fmt.Errorf("retrieving ECR authorization token: %w", err)
+	}
+
+	if len(output.AuthorizationData) == 0 || output.AuthorizationData[0].AuthorizationToken == nil {
+		return nil, errors.New("no ECR authorization token")
+	}
+
+	token, err := base64.StdEncoding.DecodeString(*output.AuthorizationData[0].AuthorizationToken)
+	if err != nil {
+		return nil, fmt.Errorf("decoding ECR authorization token: %w", err)
+	}
+
+	username, password, found := strings.Cut(string(token), ":")
+	if !found {
+		return nil, errors.New("invalid ECR authorization token")
+	}
+
+	return &authn.Basic{Username: username, Password: password}, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	containerregistry "github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCredentials(t *testing.T) {
+	dir := t.TempDir()
+
+	for content, shouldErr := range map[string]bool{
+		"ghcr.io:\n  username: bot\n  passwordEnv: GHCR_TOKEN\nteam-b:\n  username: b\n  password: secret\n": false,
+		"ghcr.io:\n  username: bot\n":                                                true,
+		"ghcr.io:\n  password: secret\n":                                             true,
+		"ghcr.io:\n  username: bot\n  password: secret\n  passwordEnv: GHCR_TOKEN\n": true,
+		"ghcr.io:\n  username: bot\n  token: secret\n":                               true,
+	} {
+		path := filepath.Join(dir, "credentials.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		_, err := LoadCredentials(path)
+		if shouldErr {
+			require.Error(t, err, content)
+		} else {
+			require.NoError(t, err, content)
+		}
+	}
+
+	_, err := LoadCredentials(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestBasicCredentials(t *testing.T) {
+	t.Setenv(RegistryCredentialsFile, "")
+	t.Setenv("TEST_USERNAME", "env-user")
+	t.Setenv("TEST_PASSWORD", "env-secret")
+
+	username, password, err := BasicCredentials("charts.example.com", false, "TEST_USERNAME", "TEST_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "env-user", username)
+	require.Equal(t, "env-secret", password)
+
+	path := filepath.Join(t.TempDir(), "credentials.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("charts.example.com:\n  username: bot\n  password: secret\n"), 0o600))
+	t.Setenv(RegistryCredentialsFile, path)
+
+	username, password, err = BasicCredentials("charts.example.com", false, "TEST_USERNAME", "TEST_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "bot", username)
+	require.Equal(t, "secret", password)
+
+	username, _, err = BasicCredentials("other.example.com", false, "TEST_USERNAME", "TEST_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "env-user", username)
+
+	_, _, err = BasicCredentials("private", true, "TEST_USERNAME", "TEST_PASSWORD")
+	require.Error(t, err)
+}
+
+func TestOptionsCredentials(t *testing.T) {
+	t.Setenv("GHCR_TOKEN", "from-env")
+
+	c := &Container{
+		Credentials: map[string]Credentials{
+			"ghcr.io": {Username: "bot", PasswordEnv: "GHCR_TOKEN"},
+			"team-b":  {Username: "b", Password: "secret"},
+		},
+		Auth: authn.Basic{Username: "global", Password: "global"},
+	}
+
+	options, err := c.options()
+	require.NoError(t, err)
+	keychain := containerregistry.GetOptions(options...).Keychain
+
+	for registry, expected := range map[string]authn.AuthConfig{
+		"ghcr.io":         {Username: "bot", Password: "from-env"},
+		"quay.io":         {Username: "global", Password: "global"},
+		"index.docker.io": {Username: "global", Password: "global"},
+	} {
+		reg, err := name.NewRegistry(registry)
+		require.NoError(t, err)
+
+		auth, err := keychain.Resolve(reg)
+		require.NoError(t, err)
+
+		config, err := auth.Authorization()
+		require.NoError(t, err)
+		require.Equal(t, expected.Username, config.Username, registry)
+		require.Equal(t, expected.Password, config.Password, registry)
+	}
+
+	c.CredentialsName = "team-b"
+	_, err = c.options()
+	require.NoError(t, err)
+
+	c.CredentialsName = "team-c"
+	_, err = c.options()
+	require.Error(t, err)
+}
+
+type fakeECR struct {
+	token string
+	err   error
+}
+
+func (f fakeECR) GetAuthorizationToken(context.Context, *ecr.GetAuthorizationTokenInput, ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &ecr.GetAuthorizationTokenOutput{
+		AuthorizationData: []types.AuthorizationData{{AuthorizationToken: &f.token}},
+	}, nil
+}
+
+func TestECRKeychain(t *testing.T) {
+	var regions []string
+	keychain := ecrKeychain{newClient: func(region string) (ECRGetAuthorizationTokenAPI, error) {
+		regions = append(regions, region)
+		return fakeECR{token: base64.StdEncoding.EncodeToString([]byte("AWS:ecr-password"))}, nil
+	}}
+
+	reg, err := name.NewRegistry("123456789012.dkr.ecr.eu-west-3.amazonaws.com")
+	require.NoError(t, err)
+
+	auth, err := keychain.Resolve(reg)
+	require.NoError(t, err)
+
+	config, err := auth.Authorization()
+	require.NoError(t, err)
+	require.Equal(t, "AWS", config.Username)
+	require.Equal(t, "ecr-password", config.Password)
+	require.Equal(t, []string{"eu-west-3"}, regions)
+
+	reg, err = name.NewRegistry("ghcr.io")
+	require.NoError(t, err)
+
+	auth, err = keychain.Resolve(reg)
+	require.NoError(t, err)
+	require.Equal(t, authn.Anonymous, auth)
+	require.Len(t, regions, 1)
+}
+
+func TestECRKeychainFallback(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	dockerConfig := `{"auths": {"123456789012.dkr.ecr.eu-west-3.amazonaws.com": {"auth": "` +
+		base64.StdEncoding.EncodeToString([]byte("docker:docker-password")) + `"}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(os.Getenv("DOCKER_CONFIG"), "config.json"), []byte(dockerConfig), 0o600))
+
+	reg, err := name.NewRegistry("123456789012.dkr.ecr.eu-west-3.amazonaws.com")
+	require.NoError(t, err)
+
+	for _, newClient := range []func(string) (ECRGetAuthorizationTokenAPI, error){
+		func(string) (ECRGetAuthorizationTokenAPI, error) { return nil, errors.New("no AWS credentials") },
+		func(string) (ECRGetAuthorizationTokenAPI, error) {
+			return fakeECR{err: errors.New("access denied")}, nil
+		},
+		func(string) (ECRGetAuthorizationTokenAPI, error) { return fakeECR{token: "not base64"}, nil },
+	} {
+		keychain := ecrKeychain{newClient: newClient}
+
+		auth, err := keychain.Resolve(reg)
+		require.NoError(t, err)
+		require.Equal(t, authn.Anonymous, auth)
+
+		// The Docker config is still tried
+		auth, err = authn.NewMultiKeychain(keychain, authn.DefaultKeychain).Resolve(reg)
+		require.NoError(t, err)
+
+		config, err := auth.Authorization()
+		require.NoError(t, err)
+		require.Equal(t, "docker", config.Username)
+		require.Equal(t, "docker-password", config.Password)
+	}
+}
+
+func TestNewECRClient(t *testing.T) {
+	c := &Container{AWSConfig: &aws.Config{Region: "us-east-1"}}
+
+	client, err := c.newECRClient("eu-west-3")
+	require.NoError(t, err)
+	require.Equal(t, "eu-west-3", client.(*ecr.Client).Options().Region)
+	require.Equal(t, "us-east-1", c.AWSConfig.Region)
+}
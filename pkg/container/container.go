@@ -17,8 +17,15 @@ limitations under the License.
 package container
 
 import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/google/go-containerregistry/pkg/authn"
 	containerregistry "github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/google"
 
 	"sigs.k8s.io/release-utils/env"
 )
@@ -32,6 +39,16 @@ const (
 type Container struct {
 	client Client
 	Auth   authn.Basic
+
+	// Credentials by registry host, or by name for CredentialsName
+	Credentials map[string]Credentials
+	// Optional: name of the Credentials to use for all registries
+	CredentialsName string
+	// Optional: AWS configuration to authenticate to ECR registries, the
+	// standard AWS SDK configuration otherwise
+	AWSConfig *aws.Config
+
+	credentialsErr error
 }
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -40,20 +57,39 @@ type Client interface {
 	ListTags(
 		src string,
 	) ([]string, error)
+	Created(
+		ref string,
+	) (time.Time, error)
+	Digest(
+		ref string,
+	) (string, error)
+	Platforms(
+		ref string,
+	) ([]string, error)
+	Artifact(
+		ref string,
+	) (map[string]string, []byte, error)
 }
 
 // New creates a new default container client. Tokens set via the $REGISTRY_USER_PASSWORD
 // and $REGISTRY_USERNAME environment variable will result in an authenticated client.
+// Credentials per registry are read from the file set via $REGISTRY_CREDENTIALS_FILE.
 func New() *Container {
 	passwd := env.Default(RegistryPassword, "")
 	username := env.Default(RegistryUserName, "")
 
-	return &Container{
+	c := &Container{
 		Auth: authn.Basic{
 			Password: passwd,
 			Username: username,
 		},
 	}
+
+	if path := env.Default(RegistryCredentialsFile, ""); path != "" {
+		c.Credentials, c.credentialsErr = LoadCredentials(path)
+	}
+
+	return c
 }
 
 // SetClient can be used to manually set the internal Container client.
@@ -70,12 +106,162 @@ func (c *Container) Client() Client {
 func (c *Container) ListTags(
 	src string,
 ) ([]string, error) {
+	options, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+
+	return containerregistry.ListTags(src, options...)
+}
+
+// Created returns the creation time recorded in the config of an image,
+// e.g. registry.k8s.io/pause:3.9.
+func (c *Container) Created(
+	ref string,
+) (time.Time, error) {
+	options, err := c.options()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	rawConfig, err := containerregistry.Config(ref, options...)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	config, err := v1.ParseConfigFile(bytes.NewReader(rawConfig))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing config of %s: %w", ref, err)
+	}
+
+	return config.Created.Time, nil
+}
+
+// Artifact returns the annotations of the manifest an image reference points
+// to, and its raw config, e.g. the metadata of a Helm chart pushed as an OCI
+// artifact.
+func (c *Container) Artifact(
+	ref string,
+) (map[string]string, []byte, error) {
+	options, err := c.options()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	img, err := containerregistry.Pull(ref, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading manifest of %s: %w", ref, err)
+	}
+
+	config, err := img.RawConfigFile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading config of %s: %w", ref, err)
+	}
+
+	return manifest.Annotations, config, nil
+}
+
+// Digest returns the digest of the manifest an image reference points to, e.g.
+// sha256:7031c1b2... for registry.k8s.io/pause:3.9. For multi-arch images,
+// it is the digest of the image index, which pins all platforms at once.
+func (c *Container) Digest(
+	ref string,
+) (string, error) {
+	options, err := c.options()
+	if err != nil {
+		return "", err
+	}
+
+	return containerregistry.Digest(ref, options...)
+}
+
+// Platforms returns the platforms an image reference is available for, e.g.
+// linux/amd64 and linux/arm64/v8: those of the manifests of an image index, or
+// the platform of a single image.
+func (c *Container) Platforms(
+	ref string,
+) ([]string, error) {
+	options, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := containerregistry.Get(ref, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !desc.MediaType.IsIndex() {
+		img, err := desc.Image()
+		if err != nil {
+			return nil, fmt.Errorf("reading image %s: %w", ref, err)
+		}
+
+		config, err := img.ConfigFile()
+		if err != nil {
+			return nil, fmt.Errorf("reading config of %s: %w", ref, err)
+		}
+
+		return []string{config.Platform().String()}, nil
+	}
+
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return nil, fmt.Errorf("reading image index %s: %w", ref, err)
+	}
+
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest of image index %s: %w", ref, err)
+	}
+
+	platforms := make([]string, 0, len(manifest.Manifests))
+	for _, m := range manifest.Manifests {
+		// Attestations are listed with an unknown platform
+		if m.Platform == nil || m.Platform.OS == "unknown" {
+			continue
+		}
+		platforms = append(platforms, m.Platform.String())
+	}
+
+	return platforms, nil
+}
+
+// options authenticates with, in order of precedence: the credentials named by
+// CredentialsName, the credentials of the registry host, the global username
+// and password, AWS ECR and Google Cloud keychains, and finally the credentials
+// configured in the docker config file.
+func (c *Container) options() ([]containerregistry.Option, error) {
+	if c.credentialsErr != nil {
+		return nil, c.credentialsErr
+	}
+
+	if c.CredentialsName != "" {
+		credentials, ok := c.Credentials[c.CredentialsName]
+		if !ok {
+			return nil, fmt.Errorf("unknown registry credentials %q", c.CredentialsName)
+		}
+
+		auth, err := credentials.authenticator()
+		if err != nil {
+			return nil, fmt.Errorf("registry credentials %s: %w", c.CredentialsName, err)
+		}
+
+		return []containerregistry.Option{containerregistry.WithAuth(auth)}, nil
+	}
+
+	keychains := []authn.Keychain{credentialsKeychain(c.Credentials)}
 	if c.Auth.Username != "" && c.Auth.Password != "" {
-		opts := containerregistry.WithAuth(&c.Auth)
-		return containerregistry.ListTags(src, opts)
+		keychains = append(keychains, basicKeychain{&c.Auth})
 	}
+	keychains = append(keychains, ecrKeychain{newClient: c.newECRClient}, google.Keychain, authn.DefaultKeychain)
 
-	// If Username/Password for the registry aren't supplied
-	// it will use the credentials configured in the docker config file.
-	return containerregistry.ListTags(src)
+	return []containerregistry.Option{
+		containerregistry.WithAuthFromKeychain(authn.NewMultiKeychain(keychains...)),
+	}, nil
 }
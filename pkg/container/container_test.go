@@ -18,8 +18,19 @@ package container_test
 
 import (
 	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/stretchr/testify/require"
 
 	"sigs.k8s.io/zeitgeist/pkg/container"
@@ -71,3 +82,64 @@ func TestTagsSuccess(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, res, 3)
 }
+
+func TestDigestLocalRegistry(t *testing.T) {
+	// Given
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	ref := strings.TrimPrefix(server.URL, "http://") + "/honk/honk:v1.0.0"
+	index, err := random.Index(64, 1, 2)
+	require.NoError(t, err)
+
+	parsed, err := name.ParseReference(ref)
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(parsed, index))
+
+	expected, err := index.Digest()
+	require.NoError(t, err)
+
+	// When
+	digest, err := container.New().Digest(ref)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), digest)
+}
+
+func TestPlatformsLocalRegistry(t *testing.T) {
+	// Given
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	repository := strings.TrimPrefix(server.URL, "http://") + "/honk/honk"
+
+	amd64, err := random.Image(64, 1)
+	require.NoError(t, err)
+	amd64, err = mutate.ConfigFile(amd64, &v1.ConfigFile{OS: "linux", Architecture: "amd64"})
+	require.NoError(t, err)
+
+	index := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}}},
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"}}},
+	)
+
+	ref, err := name.ParseReference(repository + ":multi")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(ref, index))
+
+	ref, err = name.ParseReference(repository + ":single")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, amd64))
+
+	// When
+	multi, multiErr := container.New().Platforms(repository + ":multi")
+	single, singleErr := container.New().Platforms(repository + ":single")
+
+	// Then
+	require.NoError(t, multiErr)
+	require.Equal(t, []string{"linux/amd64", "linux/arm64/v8"}, multi)
+	require.NoError(t, singleErr)
+	require.Equal(t, []string{"linux/amd64"}, single)
+}
@@ -3,11 +3,53 @@ package containerfakes
 
 import (
 	"sync"
+	"time"
 
 	"sigs.k8s.io/zeitgeist/pkg/container"
 )
 
 type FakeClient struct {
+	ArtifactStub        func(string) (map[string]string, []byte, error)
+	artifactMutex       sync.RWMutex
+	artifactArgsForCall []struct {
+		arg1 string
+	}
+	artifactReturns struct {
+		result1 map[string]string
+		result2 []byte
+		result3 error
+	}
+	artifactReturnsOnCall map[int]struct {
+		result1 map[string]string
+		result2 []byte
+		result3 error
+	}
+	CreatedStub        func(string) (time.Time, error)
+	createdMutex       sync.RWMutex
+	createdArgsForCall []struct {
+		arg1 string
+	}
+	createdReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	createdReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	DigestStub        func(string) (string, error)
+	digestMutex       sync.RWMutex
+	digestArgsForCall []struct {
+		arg1 string
+	}
+	digestReturns struct {
+		result1 string
+		result2 error
+	}
+	digestReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	ListTagsStub        func(string) ([]string, error)
 	listTagsMutex       sync.RWMutex
 	listTagsArgsForCall []struct {
@@ -21,10 +63,218 @@ type FakeClient struct {
 		result1 []string
 		result2 error
 	}
+	PlatformsStub        func(string) ([]string, error)
+	platformsMutex       sync.RWMutex
+	platformsArgsForCall []struct {
+		arg1 string
+	}
+	platformsReturns struct {
+		result1 []string
+		result2 error
+	}
+	platformsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeClient) Artifact(arg1 string) (map[string]string, []byte, error) {
+	fake.artifactMutex.Lock()
+	ret, specificReturn := fake.artifactReturnsOnCall[len(fake.artifactArgsForCall)]
+	fake.artifactArgsForCall = append(fake.artifactArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ArtifactStub
+	fakeReturns := fake.artifactReturns
+	fake.recordInvocation("Artifact", []interface{}{arg1})
+	fake.artifactMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeClient) ArtifactCallCount() int {
+	fake.artifactMutex.RLock()
+	defer fake.artifactMutex.RUnlock()
+	return len(fake.artifactArgsForCall)
+}
+
+func (fake *FakeClient) ArtifactCalls(stub func(string) (map[string]string, []byte, error)) {
+	fake.artifactMutex.Lock()
+	defer fake.artifactMutex.Unlock()
+	fake.ArtifactStub = stub
+}
+
+func (fake *FakeClient) ArtifactArgsForCall(i int) string {
+	fake.artifactMutex.RLock()
+	defer fake.artifactMutex.RUnlock()
+	argsForCall := fake.artifactArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) ArtifactReturns(result1 map[string]string, result2 []byte, result3 error) {
+	fake.artifactMutex.Lock()
+	defer fake.artifactMutex.Unlock()
+	fake.ArtifactStub = nil
+	fake.artifactReturns = struct {
+		result1 map[string]string
+		result2 []byte
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) ArtifactReturnsOnCall(i int, result1 map[string]string, result2 []byte, result3 error) {
+	fake.artifactMutex.Lock()
+	defer fake.artifactMutex.Unlock()
+	fake.ArtifactStub = nil
+	if fake.artifactReturnsOnCall == nil {
+		fake.artifactReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+			result2 []byte
+			result3 error
+		})
+	}
+	fake.artifactReturnsOnCall[i] = struct {
+		result1 map[string]string
+		result2 []byte
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) Created(arg1 string) (time.Time, error) {
+	fake.createdMutex.Lock()
+	ret, specificReturn := fake.createdReturnsOnCall[len(fake.createdArgsForCall)]
+	fake.createdArgsForCall = append(fake.createdArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CreatedStub
+	fakeReturns := fake.createdReturns
+	fake.recordInvocation("Created", []interface{}{arg1})
+	fake.createdMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) CreatedCallCount() int {
+	fake.createdMutex.RLock()
+	defer fake.createdMutex.RUnlock()
+	return len(fake.createdArgsForCall)
+}
+
+func (fake *FakeClient) CreatedCalls(stub func(string) (time.Time, error)) {
+	fake.createdMutex.Lock()
+	defer fake.createdMutex.Unlock()
+	fake.CreatedStub = stub
+}
+
+func (fake *FakeClient) CreatedArgsForCall(i int) string {
+	fake.createdMutex.RLock()
+	defer fake.createdMutex.RUnlock()
+	argsForCall := fake.createdArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) CreatedReturns(result1 time.Time, result2 error) {
+	fake.createdMutex.Lock()
+	defer fake.createdMutex.Unlock()
+	fake.CreatedStub = nil
+	fake.createdReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CreatedReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.createdMutex.Lock()
+	defer fake.createdMutex.Unlock()
+	fake.CreatedStub = nil
+	if fake.createdReturnsOnCall == nil {
+		fake.createdReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.createdReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) Digest(arg1 string) (string, error) {
+	fake.digestMutex.Lock()
+	ret, specificReturn := fake.digestReturnsOnCall[len(fake.digestArgsForCall)]
+	fake.digestArgsForCall = append(fake.digestArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DigestStub
+	fakeReturns := fake.digestReturns
+	fake.recordInvocation("Digest", []interface{}{arg1})
+	fake.digestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) DigestCallCount() int {
+	fake.digestMutex.RLock()
+	defer fake.digestMutex.RUnlock()
+	return len(fake.digestArgsForCall)
+}
+
+func (fake *FakeClient) DigestCalls(stub func(string) (string, error)) {
+	fake.digestMutex.Lock()
+	defer fake.digestMutex.Unlock()
+	fake.DigestStub = stub
+}
+
+func (fake *FakeClient) DigestArgsForCall(i int) string {
+	fake.digestMutex.RLock()
+	defer fake.digestMutex.RUnlock()
+	argsForCall := fake.digestArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) DigestReturns(result1 string, result2 error) {
+	fake.digestMutex.Lock()
+	defer fake.digestMutex.Unlock()
+	fake.DigestStub = nil
+	fake.digestReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) DigestReturnsOnCall(i int, result1 string, result2 error) {
+	fake.digestMutex.Lock()
+	defer fake.digestMutex.Unlock()
+	fake.DigestStub = nil
+	if fake.digestReturnsOnCall == nil {
+		fake.digestReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.digestReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) ListTags(arg1 string) ([]string, error) {
 	fake.listTagsMutex.Lock()
 	ret, specificReturn := fake.listTagsReturnsOnCall[len(fake.listTagsArgsForCall)]
@@ -89,11 +339,73 @@ func (fake *FakeClient) ListTagsReturnsOnCall(i int, result1 []string, result2 e
 	}{result1, result2}
 }
 
+func (fake *FakeClient) Platforms(arg1 string) ([]string, error) {
+	fake.platformsMutex.Lock()
+	ret, specificReturn := fake.platformsReturnsOnCall[len(fake.platformsArgsForCall)]
+	fake.platformsArgsForCall = append(fake.platformsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.PlatformsStub
+	fakeReturns := fake.platformsReturns
+	fake.recordInvocation("Platforms", []interface{}{arg1})
+	fake.platformsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) PlatformsCallCount() int {
+	fake.platformsMutex.RLock()
+	defer fake.platformsMutex.RUnlock()
+	return len(fake.platformsArgsForCall)
+}
+
+func (fake *FakeClient) PlatformsCalls(stub func(string) ([]string, error)) {
+	fake.platformsMutex.Lock()
+	defer fake.platformsMutex.Unlock()
+	fake.PlatformsStub = stub
+}
+
+func (fake *FakeClient) PlatformsArgsForCall(i int) string {
+	fake.platformsMutex.RLock()
+	defer fake.platformsMutex.RUnlock()
+	argsForCall := fake.platformsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) PlatformsReturns(result1 []string, result2 error) {
+	fake.platformsMutex.Lock()
+	defer fake.platformsMutex.Unlock()
+	fake.PlatformsStub = nil
+	fake.platformsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) PlatformsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.platformsMutex.Lock()
+	defer fake.platformsMutex.Unlock()
+	fake.PlatformsStub = nil
+	if fake.platformsReturnsOnCall == nil {
+		fake.platformsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.platformsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.listTagsMutex.RLock()
-	defer fake.listTagsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -58,6 +58,9 @@ type Client interface {
 	ListTags(
 		string, string, *gitlab.ListTagsOptions,
 	) ([]*gitlab.Tag, *gitlab.Response, error)
+	GetCommit(
+		string, string, string,
+	) (*gitlab.Commit, *gitlab.Response, error)
 }
 
 // New creates a new default GitLab client. Tokens set via the $GITLAB_TOKEN
@@ -132,6 +135,13 @@ func (g *gitlabClient) ListTags(owner, repo string, opt *gitlab.ListTagsOptions,
 	return tags, resp, err
 }
 
+func (g *gitlabClient) GetCommit(owner, repo, sha string,
+) (*gitlab.Commit, *gitlab.Response, error) {
+	project := fmt.Sprintf("%s/%s", owner, repo)
+	commit, resp, err := g.Commits.GetCommit(project, sha, nil)
+	return commit, resp, err
+}
+
 // SetClient can be used to manually set the internal GitLab client.
 func (g *GitLab) SetClient(client Client) {
 	g.client = client
@@ -198,3 +208,14 @@ func (g *GitLab) ListTags(owner, repo string) ([]*gitlab.Tag, error) {
 
 	return tags, nil
 }
+
+// GetCommit returns the commit with the given `sha` for the provided `owner`
+// and `repo`.
+func (g *GitLab) GetCommit(owner, repo, sha string) (*gitlab.Commit, error) {
+	commit, _, err := g.client.GetCommit(owner, repo, sha)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve GitLab commit %s for %s/%s: %w", sha, owner, repo, err)
+	}
+
+	return commit, nil
+}
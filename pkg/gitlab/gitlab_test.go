@@ -240,3 +240,30 @@ func TestListTags(t *testing.T) {
 	require.Equal(t, tag2, res[1].Name)
 	require.Equal(t, tag3, res[2].Name)
 }
+
+func TestGetCommit(t *testing.T) {
+	// Given
+	sut, client := newSUT()
+	client.GetCommitReturns(&gogitlab.Commit{ID: "abc"}, nil, nil)
+
+	// When
+	res, err := sut.GetCommit("owner", "repo", "abc")
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, "abc", res.ID)
+	_, _, sha := client.GetCommitArgsForCall(0)
+	require.Equal(t, "abc", sha)
+}
+
+func TestGetCommitFailed(t *testing.T) {
+	// Given
+	sut, client := newSUT()
+	client.GetCommitReturns(nil, nil, errors.New("error"))
+
+	// When
+	_, err := sut.GetCommit("owner", "repo", "abc")
+
+	// Then
+	require.Error(t, err)
+}
@@ -9,6 +9,23 @@ import (
 )
 
 type FakeClient struct {
+	GetCommitStub        func(string, string, string) (*gitlaba.Commit, *gitlaba.Response, error)
+	getCommitMutex       sync.RWMutex
+	getCommitArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	getCommitReturns struct {
+		result1 *gitlaba.Commit
+		result2 *gitlaba.Response
+		result3 error
+	}
+	getCommitReturnsOnCall map[int]struct {
+		result1 *gitlaba.Commit
+		result2 *gitlaba.Response
+		result3 error
+	}
 	ListBranchesStub        func(string, string, *gitlaba.ListBranchesOptions) ([]*gitlaba.Branch, *gitlaba.Response, error)
 	listBranchesMutex       sync.RWMutex
 	listBranchesArgsForCall []struct {
@@ -79,6 +96,75 @@ type FakeClient struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeClient) GetCommit(arg1 string, arg2 string, arg3 string) (*gitlaba.Commit, *gitlaba.Response, error) {
+	fake.getCommitMutex.Lock()
+	ret, specificReturn := fake.getCommitReturnsOnCall[len(fake.getCommitArgsForCall)]
+	fake.getCommitArgsForCall = append(fake.getCommitArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetCommitStub
+	fakeReturns := fake.getCommitReturns
+	fake.recordInvocation("GetCommit", []interface{}{arg1, arg2, arg3})
+	fake.getCommitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeClient) GetCommitCallCount() int {
+	fake.getCommitMutex.RLock()
+	defer fake.getCommitMutex.RUnlock()
+	return len(fake.getCommitArgsForCall)
+}
+
+func (fake *FakeClient) GetCommitCalls(stub func(string, string, string) (*gitlaba.Commit, *gitlaba.Response, error)) {
+	fake.getCommitMutex.Lock()
+	defer fake.getCommitMutex.Unlock()
+	fake.GetCommitStub = stub
+}
+
+func (fake *FakeClient) GetCommitArgsForCall(i int) (string, string, string) {
+	fake.getCommitMutex.RLock()
+	defer fake.getCommitMutex.RUnlock()
+	argsForCall := fake.getCommitArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) GetCommitReturns(result1 *gitlaba.Commit, result2 *gitlaba.Response, result3 error) {
+	fake.getCommitMutex.Lock()
+	defer fake.getCommitMutex.Unlock()
+	fake.GetCommitStub = nil
+	fake.getCommitReturns = struct {
+		result1 *gitlaba.Commit
+		result2 *gitlaba.Response
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeClient) GetCommitReturnsOnCall(i int, result1 *gitlaba.Commit, result2 *gitlaba.Response, result3 error) {
+	fake.getCommitMutex.Lock()
+	defer fake.getCommitMutex.Unlock()
+	fake.GetCommitStub = nil
+	if fake.getCommitReturnsOnCall == nil {
+		fake.getCommitReturnsOnCall = make(map[int]struct {
+			result1 *gitlaba.Commit
+			result2 *gitlaba.Response
+			result3 error
+		})
+	}
+	fake.getCommitReturnsOnCall[i] = struct {
+		result1 *gitlaba.Commit
+		result2 *gitlaba.Response
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeClient) ListBranches(arg1 string, arg2 string, arg3 *gitlaba.ListBranchesOptions) ([]*gitlaba.Branch, *gitlaba.Response, error) {
 	fake.listBranchesMutex.Lock()
 	ret, specificReturn := fake.listBranchesReturnsOnCall[len(fake.listBranchesArgsForCall)]
@@ -356,14 +442,6 @@ func (fake *FakeClient) ListTagsReturnsOnCall(i int, result1 []*gitlaba.Tag, res
 func (fake *FakeClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.listBranchesMutex.RLock()
-	defer fake.listBranchesMutex.RUnlock()
-	fake.listProjectsMutex.RLock()
-	defer fake.listProjectsMutex.RUnlock()
-	fake.listReleasesMutex.RLock()
-	defer fake.listReleasesMutex.RUnlock()
-	fake.listTagsMutex.RLock()
-	defer fake.listTagsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -19,13 +19,19 @@ package dependency
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/blang/semver/v4"
 	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
 
@@ -42,6 +48,8 @@ type RemoteClient struct {
 	AWSEC2Client EC2DescribeImagesAPI
 	AWSSSMClient upstream.SSMGetParameterAPI
 	AWSEKSClient upstream.EKSDescribeAddonVersionsAPI
+	// AWSConfig authenticates to ECR registries, of images and OCI charts
+	AWSConfig *aws.Config
 }
 
 type EC2DescribeImagesAPI interface {
@@ -53,11 +61,16 @@ func NewRemoteClient() (deppkg.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	awsConfig, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
 	return &RemoteClient{
 		LocalClient:  localClient,
 		AWSEC2Client: upstream.NewAWSClient(),
 		AWSSSMClient: upstream.NewSSMClient(),
 		AWSEKSClient: upstream.NewEKSClient(),
+		AWSConfig:    &awsConfig,
 	}, nil
 }
 
@@ -78,12 +91,29 @@ func (c *RemoteClient) RemoteCheck(dependencyFilePath string) ([]string, error)
 
 	updates := make([]string, 0)
 
-	versionUpdateInfos, err := c.CheckUpstreamVersions(externalDeps.Dependencies)
+	versionUpdateInfos, err := c.checkUpstreamVersions(externalDeps)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, vu := range versionUpdateInfos {
+		if vu.Hold != nil {
+			if vu.Held != nil {
+				updates = append(
+					updates,
+					fmt.Sprintf(
+						"Dependency %s is held at version %s until %s: %s (latest: %s)",
+						vu.Name,
+						vu.Current.Version,
+						vu.Hold.Until,
+						vu.Hold.Reason,
+						vu.Held.Version,
+					),
+				)
+			}
+			continue
+		}
+
 		if vu.UpdateAvailable {
 			updates = append(
 				updates,
@@ -102,11 +132,27 @@ func (c *RemoteClient) RemoteCheck(dependencyFilePath string) ([]string, error)
 				vu.Latest.Version,
 			)
 		}
+
+		if outsidePolicy(&vu) {
+			updates = append(
+				updates,
+				fmt.Sprintf(
+					"Update available for dependency %s outside of its auto-upgrade policy: %s (current: %s)",
+					vu.Name,
+					vu.Notify.Version,
+					vu.Current.Version,
+				),
+			)
+		}
 	}
 
 	return updates, nil
 }
 
+func (c *RemoteClient) VerifyArtifacts(dependencyFilePath string) error {
+	return c.LocalClient.VerifyArtifacts(dependencyFilePath)
+}
+
 func (c *RemoteClient) SetVersion(dependencyFilePath, basePath, dependency, version string) error {
 	return c.LocalClient.SetVersion(dependencyFilePath, basePath, dependency, version)
 }
@@ -123,9 +169,8 @@ func (c *RemoteClient) Upgrade(dependencyFilePath, basePath string) ([]string, e
 	}
 
 	upgrades := make([]string, 0)
-	upgradedDependencies := make([]*deppkg.Dependency, 0)
 
-	versionUpdateInfos, err := c.CheckUpstreamVersions(externalDeps.Dependencies)
+	versionUpdateInfos, err := c.checkUpstreamVersions(externalDeps)
 	if err != nil {
 		return nil, err
 	}
@@ -136,17 +181,65 @@ func (c *RemoteClient) Upgrade(dependencyFilePath, basePath string) ([]string, e
 			return nil, err
 		}
 
+		if vu.Held != nil {
+			log.Infof(
+				"Not upgrading dependency %s to version %s, held until %s: %s",
+				vu.Name,
+				vu.Held.Version,
+				vu.Hold.Until,
+				vu.Hold.Reason,
+			)
+			continue
+		}
+
 		if vu.UpdateAvailable {
-			err = upgradeDependency(basePath, dependency, &vu)
+			if missing := missingCompanions(dependency, &vu); len(missing) > 0 {
+				log.Warnf(
+					"Not upgrading dependency %s to version %s, no upstream value for companions %s",
+					vu.Name,
+					vu.Latest.Version,
+					strings.Join(missing, ", "),
+				)
+				continue
+			}
+
+			// Download the artifacts first, so that files are left untouched if
+			// they aren't available yet
+			checksums, err := artifactChecksums(dependency, vu.Latest.Version)
+			if err != nil {
+				log.Warnf("Not upgrading dependency %s to version %s: %v", vu.Name, vu.Latest.Version, err)
+				continue
+			}
+
+			refPaths := dependency.RefPaths
+			if vu.Entry != nil {
+				refPaths = append(slices.Clone(vu.Entry.RefPaths), dependency.RefPaths...)
+			}
+
+			err = upgradeDependency(basePath, refPaths, dependency.Companions, &vu)
 			if err != nil {
 				return nil, err
 			}
 
-			dependency.Version = vu.Latest.Version
-			upgradedDependencies = append(
-				upgradedDependencies,
-				dependency,
-			)
+			if vu.Entry != nil {
+				vu.Entry.Version = vu.Latest.Version
+			} else {
+				dependency.Version = vu.Latest.Version
+			}
+			for name := range dependency.Companions {
+				dependency.Companions[name] = vu.Companions[name]
+			}
+
+			for i, artifact := range dependency.Artifacts {
+				checksumUpdate := &deppkg.VersionUpdateInfo{
+					Current: deppkg.Version{Version: artifact.SHA256},
+					Latest:  deppkg.Version{Version: checksums[i]},
+				}
+				if err := upgradeDependency(basePath, artifact.RefPaths, nil, checksumUpdate); err != nil {
+					return nil, err
+				}
+				artifact.SHA256 = checksums[i]
+			}
 
 			upgrades = append(
 				upgrades,
@@ -158,11 +251,6 @@ func (c *RemoteClient) Upgrade(dependencyFilePath, basePath string) ([]string, e
 				),
 			)
 		} else {
-			upgradedDependencies = append(
-				upgradedDependencies,
-				dependency,
-			)
-
 			log.Debugf(
 				"No update available for dependency %s: %s (latest: %s)\n",
 				vu.Name,
@@ -173,9 +261,7 @@ func (c *RemoteClient) Upgrade(dependencyFilePath, basePath string) ([]string, e
 	}
 
 	// Update the dependencies file to reflect the upgrades
-	err = deppkg.ToFile(dependencyFilePath, &deppkg.Dependencies{
-		Dependencies: upgradedDependencies,
-	})
+	err = deppkg.ToFile(dependencyFilePath, externalDeps)
 	if err != nil {
 		return nil, err
 	}
@@ -192,10 +278,39 @@ func findDependencyByName(dependencies []*deppkg.Dependency, name string) (*depp
 	return nil, fmt.Errorf("cannot find dependency by name: %s", name)
 }
 
-func upgradeDependency(basePath string, dependency *deppkg.Dependency, versionUpdate *deppkg.VersionUpdateInfo) error {
+// artifactChecksums downloads the artifacts of a dependency at the given
+// version, and returns their checksums.
+func artifactChecksums(dependency *deppkg.Dependency, version string) ([]string, error) {
+	checksums := make([]string, 0, len(dependency.Artifacts))
+	for _, artifact := range dependency.Artifacts {
+		checksum, err := artifact.Checksum(version)
+		if err != nil {
+			return nil, err
+		}
+		checksums = append(checksums, checksum)
+	}
+
+	return checksums, nil
+}
+
+// missingCompanions returns the companions of a dependency the upstream did not
+// return a value for.
+func missingCompanions(dependency *deppkg.Dependency, versionUpdate *deppkg.VersionUpdateInfo) []string {
+	var missing []string
+	for name := range dependency.Companions {
+		if versionUpdate.Companions[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	slices.Sort(missing)
+
+	return missing
+}
+
+func upgradeDependency(basePath string, refPaths []*deppkg.RefPath, companions map[string]string, versionUpdate *deppkg.VersionUpdateInfo) error {
 	log.Debugf("running upgradeDependency, versionUpdate %#v", versionUpdate)
-	for _, refPath := range dependency.RefPaths {
-		err := replaceInFile(basePath, refPath, versionUpdate)
+	for _, refPath := range refPaths {
+		err := replaceInFile(basePath, refPath, companions, versionUpdate)
 		if err != nil {
 			return err
 		}
@@ -204,10 +319,15 @@ func upgradeDependency(basePath string, dependency *deppkg.Dependency, versionUp
 	return nil
 }
 
-func replaceInFile(basePath string, refPath *deppkg.RefPath, versionUpdate *deppkg.VersionUpdateInfo) error {
+func replaceInFile(basePath string, refPath *deppkg.RefPath, companions map[string]string, versionUpdate *deppkg.VersionUpdateInfo) error {
 	filename := filepath.Join(basePath, refPath.Path)
 	log.Debugf("running replaceInFile, refpath is %#v, versionUpdate %#v", refPath, versionUpdate)
 
+	current, latest := versionUpdate.Current.Version, versionUpdate.Latest.Version
+	if refPath.Companion != "" {
+		current, latest = companions[refPath.Companion], versionUpdate.Companions[refPath.Companion]
+	}
+
 	matcher, err := regexp.Compile(refPath.Match)
 	if err != nil {
 		return fmt.Errorf("compiling regex: %w", err)
@@ -222,17 +342,17 @@ func replaceInFile(basePath string, refPath *deppkg.RefPath, versionUpdate *depp
 
 	for i, line := range lines {
 		if matcher.MatchString(line) {
-			if strings.Contains(line, versionUpdate.Current.Version) {
+			if strings.Contains(line, current) {
 				log.Debugf(
 					"Line %d matches expected regexp %q and version %q: %s",
 					i,
 					refPath.Match,
-					versionUpdate.Current.Version,
+					current,
 					line,
 				)
 
 				// The actual upgrade:
-				lines[i] = strings.ReplaceAll(line, versionUpdate.Current.Version, versionUpdate.Latest.Version)
+				lines[i] = strings.ReplaceAll(line, current, latest)
 			}
 		}
 	}
@@ -255,17 +375,31 @@ func (c *RemoteClient) RemoteExport(dependencyFilePath string) ([]deppkg.Version
 
 	versionUpdates := []deppkg.VersionUpdate{}
 
-	versionUpdatesInfos, err := c.CheckUpstreamVersions(externalDeps.Dependencies)
+	versionUpdatesInfos, err := c.checkUpstreamVersions(externalDeps)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, vui := range versionUpdatesInfos {
+		if vui.Hold != nil {
+			if vui.Held != nil {
+				versionUpdates = append(versionUpdates, deppkg.VersionUpdate{
+					Name:       vui.Name,
+					Version:    vui.Current.Version,
+					NewVersion: vui.Held.Version,
+					HeldUntil:  vui.Hold.Until,
+					HoldReason: vui.Hold.Reason,
+				})
+			}
+			continue
+		}
+
 		if vui.UpdateAvailable {
 			versionUpdates = append(versionUpdates, deppkg.VersionUpdate{
 				Name:       vui.Name,
 				Version:    vui.Current.Version,
 				NewVersion: vui.Latest.Version,
+				Companions: vui.Companions,
 			})
 		} else {
 			log.Debugf(
@@ -275,138 +409,495 @@ func (c *RemoteClient) RemoteExport(dependencyFilePath string) ([]deppkg.Version
 				vui.Latest.Version,
 			)
 		}
+
+		if outsidePolicy(&vui) {
+			versionUpdates = append(versionUpdates, deppkg.VersionUpdate{
+				Name:          vui.Name,
+				Version:       vui.Current.Version,
+				NewVersion:    vui.Notify.Version,
+				OutsidePolicy: true,
+			})
+		}
 	}
 	return versionUpdates, nil
 }
 
 func (c *RemoteClient) CheckUpstreamVersions(deps []*deppkg.Dependency) ([]deppkg.VersionUpdateInfo, error) {
-	versionUpdates := []deppkg.VersionUpdateInfo{}
-	for _, dep := range deps {
+	return c.checkUpstreamVersions(&deppkg.Dependencies{Dependencies: deps})
+}
+
+// checkUpstreamVersions checks the upstream versions of dependencies, applying
+// the settings of their configuration file.
+func (c *RemoteClient) checkUpstreamVersions(externalDeps *deppkg.Dependencies) ([]deppkg.VersionUpdateInfo, error) {
+	order, err := evaluationOrder(externalDeps.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions the dependencies will be at, for upstreams referring to them
+	versions := make(map[string]string, len(externalDeps.Dependencies))
+	for _, dep := range externalDeps.Dependencies {
+		versions[dep.Name] = dep.Version
+		if len(dep.Versions) > 0 {
+			versions[dep.Name] = newestFirst(dep.Versions)[0].Version
+		}
+	}
+
+	// Dependencies with their defaults and upstream templates resolved
+	resolvedDeps := slices.Clone(externalDeps.Dependencies)
+	results := make([]*deppkg.VersionUpdateInfo, len(externalDeps.Dependencies))
+	entryResults := make([][]deppkg.VersionUpdateInfo, len(externalDeps.Dependencies))
+	for _, i := range order {
+		dep := externalDeps.Dependencies[i]
 		if dep.Upstream == nil {
 			continue
 		}
 
-		up := dep.Upstream
-		latestVersion := deppkg.Version{Version: dep.Version, Scheme: dep.Scheme}
-		currentVersion := deppkg.Version{Version: dep.Version, Scheme: dep.Scheme}
+		if dep.MinReleaseAge == 0 && externalDeps.MinReleaseAge > 0 {
+			withDefaults := *dep
+			withDefaults.MinReleaseAge = externalDeps.MinReleaseAge
+			dep = &withDefaults
+		}
 
-		var err error
+		upstreamParameters, err := resolveUpstream(dep, versions)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", dep.Name, err)
+		}
 
-		// Cast the flavour from the currently unknown upstream type
-		flavour := upstream.Flavour(up["flavour"])
-		switch flavour {
-		case upstream.DummyFlavour:
-			var d upstream.Dummy
+		resolved := *dep
+		resolved.Upstream = upstreamParameters
+		dep = &resolved
 
-			decodeErr := mapstructure.Decode(up, &d)
-			if decodeErr != nil {
-				return nil, decodeErr
+		if len(dep.Versions) > 0 {
+			entryResults[i], err = c.checkUpstreamVersionEntries(dep)
+			if err != nil {
+				return nil, err
 			}
+			continue
+		}
 
-			latestVersion.Version, err = d.LatestVersion()
-		case upstream.GithubFlavour:
-			var gh upstream.Github
+		versionUpdate, err := c.checkUpstreamVersion(dep)
+		if err != nil {
+			return nil, err
+		}
 
-			decodeErr := mapstructure.Decode(up, &gh)
-			if decodeErr != nil {
-				return nil, decodeErr
-			}
+		if versionUpdate.UpdateAvailable {
+			versions[dep.Name] = versionUpdate.Latest.Version
+		}
 
-			latestVersion.Version, err = gh.LatestVersion()
-		case upstream.GitLabFlavour:
-			var gl upstream.GitLab
+		resolvedDeps[i] = dep
+		results[i] = &versionUpdate
+	}
 
-			decodeErr := mapstructure.Decode(up, &gl)
-			if decodeErr != nil {
-				return nil, decodeErr
-			}
+	if err := c.resolveGroups(resolvedDeps, results); err != nil {
+		return nil, err
+	}
+
+	versionUpdates := []deppkg.VersionUpdateInfo{}
+	for i, versionUpdate := range results {
+		if versionUpdate != nil {
+			versionUpdates = append(versionUpdates, *versionUpdate)
+		}
+		versionUpdates = append(versionUpdates, entryResults[i]...)
+	}
 
-			latestVersion.Version, err = gl.LatestVersion()
-		case upstream.HelmFlavour:
-			var h upstream.Helm
+	return versionUpdates, nil
+}
 
-			decodeErr := mapstructure.Decode(up, &h)
-			if decodeErr != nil {
-				return nil, decodeErr
-			}
+// checkUpstreamVersion checks the upstream version of a single dependency.
+func (c *RemoteClient) checkUpstreamVersion(dep *deppkg.Dependency) (deppkg.VersionUpdateInfo, error) {
+	currentVersion := dep.VersionOf(dep.Version)
 
-			latestVersion.Version, err = h.LatestVersion()
-		case upstream.AMIFlavour:
-			var ami upstream.AMI
+	latest, companions, err := c.latestUpstream(dep, dep.Upstream)
+	if err != nil {
+		return deppkg.VersionUpdateInfo{}, fmt.Errorf("dependency %s: %w", dep.Name, err)
+	}
 
-			decodeErr := mapstructure.Decode(up, &ami)
-			if decodeErr != nil {
-				return nil, decodeErr
-			}
+	latestVersion := dep.VersionOf(formatVersion(dep.Version, latest))
 
-			ami.ServiceClient = c.AWSEC2Client
+	var versionUpdate deppkg.VersionUpdateInfo
+	if dep.Policy != nil {
+		versionUpdate, err = c.checkUpdatePolicy(dep, currentVersion, latestVersion, companions)
+		if err != nil {
+			return deppkg.VersionUpdateInfo{}, fmt.Errorf("comparing dependency %s: %w", dep.Name, err)
+		}
+	} else {
+		updateAvailable, err := moreSensitivelyRecent(dep, latestVersion, currentVersion, dep.Sensitivity)
+		if err != nil {
+			return deppkg.VersionUpdateInfo{}, fmt.Errorf("comparing dependency %s: %w", dep.Name, err)
+		}
 
-			latestVersion.Version, err = ami.LatestVersion()
-		case upstream.ContainerFlavour:
-			var ct upstream.Container
+		versionUpdate = deppkg.VersionUpdateInfo{
+			Name:            dep.Name,
+			Current:         currentVersion,
+			Latest:          latestVersion,
+			UpdateAvailable: updateAvailable,
+			Companions:      companions,
+		}
+	}
 
-			decodeErr := mapstructure.Decode(up, &ct)
-			if decodeErr != nil {
-				log.Debug("errr decoding")
-				return nil, decodeErr
-			}
+	versionUpdate.Companions = declaredCompanions(dep, versionUpdate.Companions)
+	applyHold(dep, &versionUpdate)
 
-			latestVersion.Version, err = ct.LatestVersion()
-		case upstream.EKSFlavour:
-			var eks upstream.EKS
+	return versionUpdate, nil
+}
 
-			decodeErr := mapstructure.Decode(up, &eks)
-			if decodeErr != nil {
-				return nil, decodeErr
-			}
+// declaredCompanions keeps the companion values the dependency refers to.
+func declaredCompanions(dep *deppkg.Dependency, companions map[string]string) map[string]string {
+	if len(dep.Companions) == 0 {
+		return nil
+	}
 
-			latestVersion.Version, err = eks.LatestVersion()
-		case upstream.EKSAddonFlavour:
-			var eksAddon upstream.EKSAddon
+	declared := make(map[string]string, len(dep.Companions))
+	for name := range dep.Companions {
+		if value, ok := companions[name]; ok {
+			declared[name] = value
+		}
+	}
 
-			decodeErr := mapstructure.Decode(up, &eksAddon)
-			if decodeErr != nil {
-				return nil, decodeErr
-			}
+	return declared
+}
 
-			eksAddon.ServiceClient = c.AWSEKSClient
+// applyHold prevents the update of a dependency while it is held.
+func applyHold(dep *deppkg.Dependency, versionUpdate *deppkg.VersionUpdateInfo) {
+	if dep.Hold == nil {
+		return
+	}
 
-			latestVersion.Version, err = eksAddon.LatestVersion()
-		case upstream.SSMFlavour:
-			var ssm upstream.SSM
+	if !dep.Hold.Active(time.Now()) {
+		log.Infof("Hold on dependency %s lapsed on %s", dep.Name, dep.Hold.Until)
+		return
+	}
 
-			decodeErr := mapstructure.Decode(up, &ssm)
-			if decodeErr != nil {
-				return nil, decodeErr
-			}
+	versionUpdate.Hold = dep.Hold
+	versionUpdate.Held = heldUpdate(versionUpdate)
+	versionUpdate.UpdateAvailable = false
+	versionUpdate.NotifyAvailable = false
+}
 
-			ssm.ServiceClient = c.AWSSSMClient
+// latestUpstreamVersion retrieves the latest version of a dependency from the
+// given upstream parameters.
+func (c *RemoteClient) latestUpstreamVersion(dep *deppkg.Dependency, up map[string]string) (string, error) {
+	version, _, err := c.latestUpstream(dep, up)
+	return version, err
+}
+
+// latestUpstream retrieves the latest version of a dependency from the given
+// upstream parameters, along with its companion values if the upstream has any.
+func (c *RemoteClient) latestUpstream(dep *deppkg.Dependency, up map[string]string) (string, map[string]string, error) {
+	// Cast the flavour from the currently unknown upstream type
+	flavour := upstream.Flavour(up["flavour"])
+	filter := upstream.Filter{
+		MinReleaseAge:  dep.MinReleaseAge,
+		IgnoreVersions: dep.IgnoreVersions,
+	}
+
+	current := dep.Version
+	if current == "" && len(dep.Versions) > 0 {
+		current = newestFirst(dep.Versions)[0].Version
+	}
+	filter.Current, _ = deppkg.SplitDigest(current)
 
-			latestVersion.Version, err = ssm.LatestVersion()
-		default:
-			return nil, fmt.Errorf("unknown upstream flavour '%#v' for dependency %s", flavour, dep.Name)
+	if include, set := dep.IncludesPrereleases(current); set {
+		filter.Prereleases = upstream.PrereleasesExclude
+		if include {
+			filter.Prereleases = upstream.PrereleasesInclude
 		}
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("dependency %s: %w", dep.Name, err)
+	if dep.Scheme == deppkg.Calver || dep.Scheme == deppkg.Custom {
+		filter.MoreRecent = func(a, b string) (bool, error) {
+			return dep.VersionOf(a).MoreRecentThan(dep.VersionOf(b))
 		}
+	}
+
+	var (
+		version    string
+		companions map[string]string
+		err        error
+	)
+
+	switch flavour {
+	case upstream.DummyFlavour:
+		var d upstream.Dummy
+
+		decodeErr := mapstructure.Decode(up, &d)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		d.Filter = filter
+
+		version, err = d.LatestVersion()
+	case upstream.GithubFlavour:
+		var gh upstream.Github
+
+		decodeErr := mapstructure.Decode(up, &gh)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		gh.Filter = filter
+
+		version, companions, err = gh.LatestVersionCompanions()
+	case upstream.GitLabFlavour:
+		var gl upstream.GitLab
+
+		decodeErr := mapstructure.Decode(up, &gl)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		gl.Filter = filter
+
+		version, err = gl.LatestVersion()
+	case upstream.GitFlavour:
+		var g upstream.Git
+
+		decodeErr := mapstructure.Decode(up, &g)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		g.Filter = filter
+
+		version, err = g.LatestVersion()
+	case upstream.HelmFlavour:
+		var h upstream.Helm
+
+		decodeErr := mapstructure.Decode(up, &h)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		h.AWSConfig = c.AWSConfig
+		h.Filter = filter
+
+		version, companions, err = h.LatestVersionCompanions()
+	case upstream.AMIFlavour:
+		var ami upstream.AMI
 
-		latestVersion.Version = formatVersion(dep.Version, latestVersion.Version)
+		decodeErr := mapstructure.Decode(up, &ami)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		ami.ServiceClient = c.AWSEC2Client
+		ami.Filter = filter
+
+		version, err = ami.LatestVersion()
+	case upstream.ContainerFlavour:
+		var ct upstream.Container
+
+		decodeErr := mapstructure.Decode(up, &ct)
+		if decodeErr != nil {
+			log.Debug("errr decoding")
+			return "", nil, decodeErr
+		}
+
+		ct.AWSConfig = c.AWSConfig
+		ct.Filter = filter
+
+		version, err = ct.LatestVersion()
+	case upstream.EKSFlavour:
+		var eks upstream.EKS
+
+		decodeErr := mapstructure.Decode(up, &eks)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		eks.Filter = filter
+
+		version, err = eks.LatestVersion()
+	case upstream.EKSAddonFlavour:
+		var eksAddon upstream.EKSAddon
+
+		decodeErr := mapstructure.Decode(up, &eksAddon)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		eksAddon.ServiceClient = c.AWSEKSClient
+		eksAddon.Filter = filter
+
+		version, err = eksAddon.LatestVersion()
+	case upstream.SSMFlavour:
+		var ssm upstream.SSM
+
+		decodeErr := mapstructure.Decode(up, &ssm)
+		if decodeErr != nil {
+			return "", nil, decodeErr
+		}
+
+		ssm.ServiceClient = c.AWSSSMClient
+		ssm.Filter = filter
+
+		version, err = ssm.LatestVersion()
+	default:
+		return "", nil, fmt.Errorf("unknown upstream flavour '%#v' for dependency %s", flavour, dep.Name)
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Flavours returning a single version cannot skip ignored ones, so stay on
+	// the current version instead
+	if filter.Ignores(version) {
+		log.Debugf("Latest version %s of dependency %s is ignored", version, dep.Name)
+		return current, dep.Companions, nil
+	}
+
+	return version, companions, nil
+}
+
+// checkUpdatePolicy finds the version `upgrade` may apply under the dependency's
+// update policy, and whether the latest upstream version is worth a notification.
+//
+// When the latest version goes beyond the policy's autoUpgrade level, the upstream
+// is queried again with its constraints narrowed down to that level, so that e.g.
+// the newest 1.4.x is still proposed when 2.0.0 exists.
+func (c *RemoteClient) checkUpdatePolicy(dep *deppkg.Dependency, current, latest deppkg.Version, companions map[string]string) (deppkg.VersionUpdateInfo, error) {
+	notifyLevel := dep.Policy.Notify
+	if notifyLevel == "" {
+		notifyLevel = dep.Sensitivity
+	}
 
-		updateAvailable, err := latestVersion.MoreSensitivelyRecentThan(currentVersion, dep.Sensitivity)
+	notifyAvailable, err := moreSensitivelyRecent(dep, latest, current, notifyLevel)
+	if err != nil {
+		return deppkg.VersionUpdateInfo{}, err
+	}
+
+	candidate := latest
+	updateLevel := notifyLevel
+	if dep.Policy.AutoUpgrade != "" {
+		updateLevel = deppkg.Patch
+
+		candidate, companions, err = c.policyCandidate(dep, current, latest, companions)
 		if err != nil {
-			return nil, fmt.Errorf("comparing dependency %s: %w", dep.Name, err)
+			return deppkg.VersionUpdateInfo{}, err
 		}
+	}
 
-		versionUpdates = append(versionUpdates, deppkg.VersionUpdateInfo{
-			Name:            dep.Name,
-			Current:         currentVersion,
-			Latest:          latestVersion,
-			UpdateAvailable: updateAvailable,
-		})
+	updateAvailable, err := moreSensitivelyRecent(dep, candidate, current, updateLevel)
+	if err != nil {
+		return deppkg.VersionUpdateInfo{}, err
 	}
 
-	return versionUpdates, nil
+	return deppkg.VersionUpdateInfo{
+		Name:            dep.Name,
+		Current:         current,
+		Latest:          candidate,
+		UpdateAvailable: updateAvailable,
+		Notify:          &latest,
+		NotifyAvailable: notifyAvailable,
+		Companions:      companions,
+	}, nil
+}
+
+// policyCandidate returns the highest upstream version within the policy's
+// autoUpgrade level and its companion values, or the current version if there
+// is none.
+func (c *RemoteClient) policyCandidate(dep *deppkg.Dependency, current, latest deppkg.Version, companions map[string]string) (deppkg.Version, map[string]string, error) {
+	within, err := latest.WithinSensitivity(current, dep.Policy.AutoUpgrade)
+	if err != nil || within {
+		return latest, companions, err
+	}
+
+	constraints, err := sensitivityConstraints(current, dep.Policy.AutoUpgrade)
+	if err != nil {
+		return deppkg.Version{}, nil, err
+	}
+
+	up := make(map[string]string, len(dep.Upstream)+1)
+	for k, v := range dep.Upstream {
+		up[k] = v
+	}
+	up["constraints"] = mergeConstraints(dep.Upstream["constraints"], constraints)
+
+	log.Debugf("Latest version %s of dependency %s is outside of its auto-upgrade policy, retrying with constraints %q", latest.Version, dep.Name, up["constraints"])
+	version, companions, err := c.latestUpstream(dep, up)
+	if errors.Is(err, upstream.ErrNoVersion) {
+		log.Debugf("No version of dependency %s within its auto-upgrade policy", dep.Name)
+		return current, dep.Companions, nil
+	}
+	if err != nil {
+		return deppkg.Version{}, nil, fmt.Errorf("retrieving version within auto-upgrade policy: %w", err)
+	}
+
+	candidate := dep.VersionOf(formatVersion(dep.Version, version))
+
+	// Not every flavour supports constraints, so double-check the result
+	within, err = candidate.WithinSensitivity(current, dep.Policy.AutoUpgrade)
+	if err != nil {
+		return deppkg.Version{}, nil, err
+	}
+	if !within {
+		log.Debugf("Upstream of dependency %s has no version within its auto-upgrade policy", dep.Name)
+		return current, dep.Companions, nil
+	}
+
+	return candidate, companions, nil
+}
+
+// sensitivityConstraints returns a semver range matching the versions that are
+// within the given sensitivity of the current version, e.g. "< 1.5.0-0" for 1.4.2
+// with a Patch sensitivity.
+func sensitivityConstraints(current deppkg.Version, sensitivity deppkg.VersionSensitivity) (string, error) {
+	version, _ := deppkg.SplitDigest(current.Version)
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return "", err
+	}
+
+	switch sensitivity {
+	case deppkg.Major:
+		return "", nil
+	case deppkg.Minor:
+		return fmt.Sprintf("< %d.0.0-0", v.Major+1), nil
+	case deppkg.Patch, "":
+		return fmt.Sprintf("< %d.%d.0-0", v.Major, v.Minor+1), nil
+	default:
+		return "", fmt.Errorf("unknown version sensitivity: %s", sensitivity)
+	}
+}
+
+// mergeConstraints combines two semver ranges so that versions must match both.
+func mergeConstraints(constraints, extra string) string {
+	if strings.TrimSpace(constraints) == "" {
+		return extra
+	}
+	if extra == "" {
+		return constraints
+	}
+
+	// AND binds tighter than OR, so the extra range is added to every alternative
+	alternatives := strings.Split(constraints, "||")
+	for i, alternative := range alternatives {
+		alternatives[i] = strings.TrimSpace(alternative) + " " + extra
+	}
+	return strings.Join(alternatives, " || ")
+}
+
+// heldUpdate returns the version a held dependency would otherwise be
+// updated to or reported at, if any.
+func heldUpdate(versionUpdate *deppkg.VersionUpdateInfo) *deppkg.Version {
+	if versionUpdate.NotifyAvailable {
+		return versionUpdate.Notify
+	}
+
+	if versionUpdate.UpdateAvailable {
+		return &versionUpdate.Latest
+	}
+
+	return nil
+}
+
+// outsidePolicy returns whether a version update includes a notification about
+// a more recent version than the one its auto-upgrade policy allows.
+func outsidePolicy(versionUpdate *deppkg.VersionUpdateInfo) bool {
+	return versionUpdate.NotifyAvailable && versionUpdate.Notify.Version != versionUpdate.Latest.Version
 }
 
 // formatVersion preserves the string formatting from the template and ensures the version
@@ -420,3 +911,31 @@ func formatVersion(template, version string) string {
 	}
 	return strings.TrimPrefix(version, "v")
 }
+
+// moreSensitivelyRecent checks whether the latest version of a dependency is more
+// recent than the current one at the given sensitivity. Tags of a container
+// variant moving between codenames, e.g. 3.12.4-slim-bookworm, are compared by
+// version and then by codename, so that a newer codename of the same version is
+// an update.
+func moreSensitivelyRecent(dep *deppkg.Dependency, latest, current deppkg.Version, sensitivity deppkg.VersionSensitivity) (bool, error) {
+	if upstream.Flavour(dep.Upstream["flavour"]) == upstream.ContainerFlavour {
+		var ct upstream.Container
+		if err := mapstructure.Decode(dep.Upstream, &ct); err != nil {
+			return false, err
+		}
+
+		latestTag, _ := deppkg.SplitDigest(latest.Version)
+		currentTag, _ := deppkg.SplitDigest(current.Version)
+		latestBase, latestRank, latestOK := ct.SplitVariant(latestTag)
+		currentBase, currentRank, currentOK := ct.SplitVariant(currentTag)
+		if latestOK && currentOK {
+			if latestBase == currentBase {
+				return latestRank > currentRank, nil
+			}
+
+			return dep.VersionOf(latestBase).MoreSensitivelyRecentThan(dep.VersionOf(currentBase), sensitivity)
+		}
+	}
+
+	return latest.MoreSensitivelyRecentThan(current, sensitivity)
+}
@@ -18,14 +18,28 @@ package dependency
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/stretchr/testify/require"
 
 	deppkg "sigs.k8s.io/zeitgeist/dependency"
@@ -247,6 +261,12 @@ func TestFormatVersion(t *testing.T) {
 			version:  "2.0.0",
 			want:     "2.0.0",
 		},
+		{
+			name:     "Pre-release of the template is dropped",
+			template: "1.0.0-rc.1",
+			version:  "1.0.0",
+			want:     "1.0.0",
+		},
 	}
 
 	for _, tt := range tests {
@@ -402,3 +422,1090 @@ dependencies:
 	require.NoError(t, err)
 	require.Equal(t, "VERSION: 1.0.0\nOTHER: 0.0.1", string(got))
 }
+
+// helmRepoServer serves the Helm repository index from the testdata folder.
+func helmRepoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/index.yaml" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeFile(rw, req, "../../testdata/helm-repo/index.yaml")
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestUpgradeCompanions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, `apiVersion: v2
+entries:
+  app:
+  - apiVersion: v2
+    appVersion: 1.7.0
+    created: "2021-01-01T00:00:00Z"
+    name: app
+    version: 3.0.0
+  - apiVersion: v2
+    appVersion: 1.6.0
+    created: "2021-01-01T00:00:00Z"
+    name: app
+    version: 2.1.0
+`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+
+	err := os.WriteFile(testFile, []byte("IMAGE: app:1.5.0\nCHART: 2.0.0"), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(fmt.Sprintf(`
+dependencies:
+  - name: app
+    version: 1.5.0
+    upstream:
+      flavour: helm
+      repo: %s
+      chart: app
+      track: appVersion
+      constraints: < 1.7.0
+    companions:
+      chartVersion: 2.0.0
+    refPaths:
+    - path: test.txt
+      match: IMAGE
+    - path: test.txt
+      match: CHART
+      companion: chartVersion
+`, server.URL)), 0o644)
+	require.NoError(t, err)
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	err = client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+
+	ret, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Upgraded dependency app from version 1.5.0 to version 1.6.0"}, ret)
+
+	got, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	require.Equal(t, "IMAGE: app:1.6.0\nCHART: 2.1.0", string(got))
+
+	externalDeps, err := deppkg.FromFile(filepath.Join(dir, "dependencies.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"chartVersion": "2.1.0"}, externalDeps.Dependencies[0].Companions)
+
+	err = client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+}
+
+func TestCheckUpstreamVersionsPolicy(t *testing.T) {
+	server := helmRepoServer(t)
+
+	deps := []*deppkg.Dependency{
+		{
+			Name:    "chart",
+			Version: "1.5.0",
+			Scheme:  deppkg.Semver,
+			Policy: &deppkg.UpdatePolicy{
+				AutoUpgrade: deppkg.Patch,
+				Notify:      deppkg.Major,
+			},
+			Upstream: map[string]string{
+				"flavour": "helm",
+				"repo":    server.URL,
+				"chart":   "dependency-two",
+			},
+		},
+		{
+			// The dummy flavour has no constraints, so only the latest version is known
+			Name:    "dummy",
+			Version: "1.4.0",
+			Scheme:  deppkg.Semver,
+			Policy: &deppkg.UpdatePolicy{
+				AutoUpgrade: deppkg.Minor,
+			},
+			Upstream: map[string]string{
+				"flavour": "dummy",
+				"latest":  "2.0.0",
+			},
+		},
+	}
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+	updateInfos, err := client.CheckUpstreamVersions(deps)
+	require.NoError(t, err)
+	require.Len(t, updateInfos, 2)
+
+	require.True(t, updateInfos[0].UpdateAvailable)
+	require.Equal(t, "1.5.1", updateInfos[0].Latest.Version)
+	require.True(t, updateInfos[0].NotifyAvailable)
+	require.Equal(t, "2.0.0", updateInfos[0].Notify.Version)
+
+	require.False(t, updateInfos[1].UpdateAvailable)
+	require.Equal(t, "1.4.0", updateInfos[1].Latest.Version)
+	require.True(t, updateInfos[1].NotifyAvailable)
+	require.Equal(t, "2.0.0", updateInfos[1].Notify.Version)
+}
+
+func TestCheckUpstreamVersionsPolicyRetry(t *testing.T) {
+	// Releases are only listed successfully failAfter times, if set
+	githubServer := func(failAfter int32) *httptest.Server {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/api/v3/repos/team/tool":
+				fmt.Fprint(rw, `{"name": "tool"}`)
+			case "/api/v3/repos/team/tool/releases":
+				if failAfter > 0 && requests.Add(1) > failAfter {
+					rw.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				fmt.Fprint(rw, `[{"tag_name": "v2.1.0"}, {"tag_name": "v2.0.0"}]`)
+			default:
+				rw.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	dependency := func(server *httptest.Server) *deppkg.Dependency {
+		return &deppkg.Dependency{
+			Name:     "tool",
+			Version:  "v1.4.0",
+			Scheme:   deppkg.Semver,
+			Policy:   &deppkg.UpdatePolicy{AutoUpgrade: deppkg.Minor},
+			Upstream: map[string]string{"flavour": "github", "server": server.URL, "url": "team/tool"},
+		}
+	}
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	// No version within the policy keeps the current one
+	updateInfos, err := client.CheckUpstreamVersions([]*deppkg.Dependency{dependency(githubServer(0))})
+	require.NoError(t, err)
+	require.Len(t, updateInfos, 1)
+	require.False(t, updateInfos[0].UpdateAvailable)
+	require.Equal(t, "v1.4.0", updateInfos[0].Latest.Version)
+	require.True(t, updateInfos[0].NotifyAvailable)
+
+	// Other errors aren't mistaken for the lack of a version
+	_, err = client.CheckUpstreamVersions([]*deppkg.Dependency{dependency(githubServer(1))})
+	require.Error(t, err)
+}
+
+func TestUpgradePolicy(t *testing.T) {
+	server := helmRepoServer(t)
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+
+	err := os.WriteFile(testFile, []byte("CHART_VERSION: 1.5.0"), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(`
+dependencies:
+  - name: chart
+    version: 1.5.0
+    policy:
+      autoUpgrade: patch
+      notify: major
+    upstream:
+      flavour: helm
+      repo: `+server.URL+`
+      chart: dependency-two
+    refPaths:
+    - path: test.txt
+      match: CHART_VERSION
+`), 0o644)
+	require.NoError(t, err)
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	updates, err := client.RemoteExport(filepath.Join(dir, "dependencies.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, []deppkg.VersionUpdate{
+		{Name: "chart", Version: "1.5.0", NewVersion: "1.5.1"},
+		{Name: "chart", Version: "1.5.0", NewVersion: "2.0.0", OutsidePolicy: true},
+	}, updates)
+
+	ret, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Upgraded dependency chart from version 1.5.0 to version 1.5.1"}, ret)
+
+	got, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	require.Equal(t, "CHART_VERSION: 1.5.1", string(got))
+}
+
+func TestUpgradeKeepsConfiguration(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+
+	err := os.WriteFile(testFile, []byte("VERSION: 0.0.1\nOTHER: 0.0.1"), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(`
+minReleaseAge: 72h
+dependencies:
+  - name: upgrade
+    version: 0.0.1
+    upstream:
+      flavour: dummy
+    refPaths:
+    - path: test.txt
+      match: VERSION
+  - name: no-upstream
+    version: 0.0.1
+    refPaths:
+    - path: test.txt
+      match: OTHER
+`), 0o644)
+	require.NoError(t, err)
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+	_, err = client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+
+	externalDeps, err := deppkg.FromFile(filepath.Join(dir, "dependencies.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, 72*time.Hour, externalDeps.MinReleaseAge)
+	require.Len(t, externalDeps.Dependencies, 2)
+	require.Equal(t, "1.0.0", externalDeps.Dependencies[0].Version)
+	require.Equal(t, "0.0.1", externalDeps.Dependencies[1].Version)
+}
+
+func TestCheckUpstreamVersionsIgnoreVersions(t *testing.T) {
+	server := helmRepoServer(t)
+
+	deps := []*deppkg.Dependency{
+		{
+			Name:           "chart",
+			Version:        "1.5.0",
+			Scheme:         deppkg.Semver,
+			IgnoreVersions: []string{">=2.0.0"},
+			Upstream: map[string]string{
+				"flavour": "helm",
+				"repo":    server.URL,
+				"chart":   "dependency-two",
+			},
+		},
+		{
+			// The dummy flavour only knows its latest version, so it stays current
+			Name:           "dummy",
+			Version:        "0.0.1",
+			Scheme:         deppkg.Semver,
+			IgnoreVersions: []string{"1.0.0"},
+			Upstream: map[string]string{
+				"flavour": "dummy",
+			},
+		},
+		{
+			// Several versions stay on their newest one
+			Name:           "matrix",
+			Versions:       []*deppkg.VersionEntry{{Version: "0.0.1"}, {Version: "0.1.2"}},
+			VersionsMode:   deppkg.VersionsRolling,
+			Scheme:         deppkg.Semver,
+			IgnoreVersions: []string{"1.0.0"},
+			Upstream: map[string]string{
+				"flavour": "dummy",
+			},
+		},
+	}
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+	updateInfos, err := client.CheckUpstreamVersions(deps)
+	require.NoError(t, err)
+	require.Len(t, updateInfos, 4)
+
+	require.True(t, updateInfos[0].UpdateAvailable)
+	require.Equal(t, "1.5.1", updateInfos[0].Latest.Version)
+
+	require.False(t, updateInfos[1].UpdateAvailable)
+	require.Equal(t, "0.0.1", updateInfos[1].Latest.Version)
+
+	require.False(t, updateInfos[2].UpdateAvailable)
+	require.Equal(t, "0.1.2", updateInfos[2].Latest.Version)
+	require.False(t, updateInfos[3].UpdateAvailable)
+	require.Equal(t, "0.0.1", updateInfos[3].Latest.Version)
+}
+
+func TestHold(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+
+	err := os.WriteFile(testFile, []byte("HELD: 0.0.1\nLAPSED: 0.0.1"), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(`
+dependencies:
+  - name: held
+    version: 0.0.1
+    hold:
+      until: 2999-01-01
+      reason: waiting for a fix
+    upstream:
+      flavour: dummy
+    refPaths:
+    - path: test.txt
+      match: HELD
+  - name: lapsed
+    version: 0.0.1
+    hold:
+      until: 2000-01-01
+    upstream:
+      flavour: dummy
+    refPaths:
+    - path: test.txt
+      match: LAPSED
+`), 0o644)
+	require.NoError(t, err)
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	updates, err := client.RemoteCheck(filepath.Join(dir, "dependencies.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Dependency held is held at version 0.0.1 until 2999-01-01: waiting for a fix (latest: 1.0.0)",
+		"Update available for dependency lapsed: 1.0.0 (current: 0.0.1)",
+	}, updates)
+
+	exported, err := client.RemoteExport(filepath.Join(dir, "dependencies.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, []deppkg.VersionUpdate{
+		{Name: "held", Version: "0.0.1", NewVersion: "1.0.0", HeldUntil: "2999-01-01", HoldReason: "waiting for a fix"},
+		{Name: "lapsed", Version: "0.0.1", NewVersion: "1.0.0"},
+	}, exported)
+
+	upgrades, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Upgraded dependency lapsed from version 0.0.1 to version 1.0.0"}, upgrades)
+
+	got, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	require.Equal(t, "HELD: 0.0.1\nLAPSED: 1.0.0", string(got))
+}
+
+func TestRelativeConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		constraints string
+		current     string
+		expected    string
+	}{
+		{"", "1.28.3", ""},
+		{">= 1.0.0", "1.28.3", ">= 1.0.0"},
+		{"same-major", "1.28.3", ">= 1.0.0-0 < 2.0.0-0"},
+		{"same-minor", "v1.28.3", ">= 1.28.0-0 < 1.29.0-0"},
+		{"same-minor", "v1.15.1-eksbuild.1", ">= 1.15.0-0 < 1.16.0-0"},
+		{"< {{ .Major | add 1 }}.0.0", "1.28.3", "< 2.0.0"},
+		{">= {{ .Major }}.{{ .Minor | sub 1 }}.0 < {{ .Major }}.{{ .Minor | add 1 }}.0", "1.28.3", ">= 1.27.0 < 1.29.0"},
+	} {
+		resolved, err := relativeConstraints(tc.constraints, tc.current, templateFuncs(nil))
+		require.NoError(t, err, tc.constraints)
+		require.Equal(t, tc.expected, resolved, tc.constraints)
+	}
+
+	for _, tc := range []struct {
+		constraints string
+		current     string
+	}{
+		{"same-major", "latest"},
+		{"< {{ .Major | add 1 }.0.0", "1.28.3"},
+		{"< {{ .Unknown }}", "1.28.3"},
+		{"< {{ .Minor | sub 1 }}", "1.0.0"},
+	} {
+		_, err := relativeConstraints(tc.constraints, tc.current, templateFuncs(nil))
+		require.Error(t, err, tc.constraints)
+	}
+}
+
+func TestCheckUpstreamVersionsRelativeConstraints(t *testing.T) {
+	server := helmRepoServer(t)
+
+	deps := []*deppkg.Dependency{
+		{
+			Name:    "chart",
+			Version: "1.5.0",
+			Scheme:  deppkg.Semver,
+			Upstream: map[string]string{
+				"flavour":     "helm",
+				"repo":        server.URL,
+				"chart":       "dependency-two",
+				"constraints": "< {{ .Major | add 1 }}.0.0",
+			},
+		},
+	}
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+	updateInfos, err := client.CheckUpstreamVersions(deps)
+	require.NoError(t, err)
+	require.Len(t, updateInfos, 1)
+	require.True(t, updateInfos[0].UpdateAvailable)
+	require.Equal(t, "1.5.1", updateInfos[0].Latest.Version)
+
+	// The configured constraints are left untouched
+	require.Equal(t, "< {{ .Major | add 1 }}.0.0", deps[0].Upstream["constraints"])
+}
+
+func TestUpgradeCrossDependencyReferences(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+
+	err := os.WriteFile(testFile, []byte("ADDON: 1.30.0-eksbuild.1\nKUBERNETES: 1.30.4"), 0o644)
+	require.NoError(t, err)
+
+	// The add-on comes first, but is resolved with the upgraded Kubernetes version
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(`
+dependencies:
+  - name: addon
+    version: 1.30.0-eksbuild.1
+    upstream:
+      flavour: dummy
+      latest: '{{ dep "kubernetes" | majorMinor }}.0-eksbuild.1'
+    refPaths:
+    - path: test.txt
+      match: ADDON
+  - name: kubernetes
+    version: 1.30.4
+    upstream:
+      flavour: dummy
+      latest: 1.31.2
+    refPaths:
+    - path: test.txt
+      match: KUBERNETES
+`), 0o644)
+	require.NoError(t, err)
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	upgrades, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Upgraded dependency addon from version 1.30.0-eksbuild.1 to version 1.31.0-eksbuild.1",
+		"Upgraded dependency kubernetes from version 1.30.4 to version 1.31.2",
+	}, upgrades)
+
+	got, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	require.Equal(t, "ADDON: 1.31.0-eksbuild.1\nKUBERNETES: 1.31.2", string(got))
+
+	// The template is kept in the configuration
+	externalDeps, err := deppkg.FromFile(filepath.Join(dir, "dependencies.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, `{{ dep "kubernetes" | majorMinor }}.0-eksbuild.1`, externalDeps.Dependencies[0].Upstream["latest"])
+}
+
+func TestCheckUpstreamVersionsInvalidReferences(t *testing.T) {
+	for _, deps := range [][]*deppkg.Dependency{
+		{
+			{Name: "a", Version: "1.0.0", Upstream: map[string]string{"flavour": "dummy", "latest": `{{ dep "b" }}`}},
+			{Name: "b", Version: "1.0.0", Upstream: map[string]string{"flavour": "dummy", "latest": `{{ dep "a" }}`}},
+		},
+		{
+			{Name: "a", Version: "1.0.0", Upstream: map[string]string{"flavour": "dummy", "latest": `{{ dep "unknown" }}`}},
+		},
+		{
+			{Name: "a", Version: "1.0.0", Upstream: map[string]string{"flavour": "dummy", "latest": `{{ dep "b" | majorMinor }}`}},
+			{Name: "b", Version: "latest"},
+		},
+	} {
+		client, err := NewRemoteClient()
+		require.NoError(t, err)
+		_, err = client.CheckUpstreamVersions(deps)
+		require.Error(t, err)
+	}
+}
+
+// githubServer serves the releases of team/tool as a GitHub Enterprise Server:
+// v2.0.0 has no binaries, v1.3.0 and v1.2.0 have binaries and checksums.
+func githubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v3/repos/team/tool":
+			fmt.Fprint(rw, `{"name": "tool"}`)
+		case "/api/v3/repos/team/tool/releases":
+			fmt.Fprint(rw, `[
+  {"tag_name": "v2.0.0", "assets": [{"id": 3, "name": "tool_2.0.0_SHA256SUMS"}]},
+  {"tag_name": "v1.3.0", "assets": [
+    {"id": 1, "name": "tool_1.3.0_linux_amd64.tar.gz"},
+    {"id": 2, "name": "tool_1.3.0_SHA256SUMS"}
+  ]},
+  {"tag_name": "v1.2.0", "assets": [{"id": 4, "name": "tool_1.2.0_linux_amd64.tar.gz"}]}
+]`)
+		case "/api/v3/repos/team/tool/releases/assets/2":
+			fmt.Fprint(rw, strings.Repeat("a", 64)+"  tool_1.3.0_linux_amd64.tar.gz\n")
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCheckUpstreamVersionsUpstreamTemplates(t *testing.T) {
+	server := githubServer(t)
+
+	for _, tc := range []struct {
+		name       string
+		version    string
+		upstream   map[string]string
+		companions map[string]string
+		latest     string
+	}{
+		{
+			name:     "tag",
+			version:  "v1.2.0",
+			upstream: map[string]string{"versionTemplate": "{{ .Tag }}"},
+			latest:   "v2.0.0",
+		},
+		{
+			name:     "major",
+			version:  "v1",
+			upstream: map[string]string{"versionTemplate": "v{{ .Major }}"},
+			latest:   "v2",
+		},
+		{
+			name:    "asset and checksums",
+			version: "v1.2.0",
+			upstream: map[string]string{
+				"asset":     "tool_{{ .Version }}_linux_*.tar.gz",
+				"checksums": "tool_{{ .Version }}_SHA256SUMS",
+			},
+			companions: map[string]string{"sha256": strings.Repeat("b", 64)},
+			latest:     "v1.3.0",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.upstream["flavour"] = "github"
+			tc.upstream["server"] = server.URL
+			tc.upstream["url"] = "team/tool"
+
+			deps := []*deppkg.Dependency{
+				{
+					Name:       "tool",
+					Version:    tc.version,
+					Scheme:     deppkg.Semver,
+					Upstream:   tc.upstream,
+					Companions: tc.companions,
+				},
+			}
+
+			client, err := NewRemoteClient()
+			require.NoError(t, err)
+			updateInfos, err := client.CheckUpstreamVersions(deps)
+			require.NoError(t, err)
+			require.Len(t, updateInfos, 1)
+			require.True(t, updateInfos[0].UpdateAvailable)
+			require.Equal(t, tc.latest, updateInfos[0].Latest.Version)
+			if tc.companions != nil {
+				require.Equal(t, map[string]string{"sha256": strings.Repeat("a", 64)}, updateInfos[0].Companions)
+			}
+		})
+	}
+}
+
+func TestUpgradeDiscoveredActions(t *testing.T) {
+	server := githubServer(t)
+
+	dir := t.TempDir()
+	workflow := filepath.Join(dir, ".github", "workflows", "ci.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(workflow), 0o755))
+	require.NoError(t, os.WriteFile(workflow, []byte("steps:\n  - uses: team/tool@v1\n"), 0o644))
+
+	discovered, err := deppkg.DiscoverActions(dir, ".github")
+	require.NoError(t, err)
+	require.Len(t, discovered.Dependencies, 1)
+	discovered.Dependencies[0].Upstream["server"] = server.URL
+	require.NoError(t, deppkg.ToFile(filepath.Join(dir, "dependencies.yaml"), discovered))
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	upgrades, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Upgraded dependency team/tool from version v1 to version v2"}, upgrades)
+
+	got, err := os.ReadFile(workflow)
+	require.NoError(t, err)
+	require.Equal(t, "steps:\n  - uses: team/tool@v2\n", string(got))
+}
+
+func TestCheckUpstreamVersionsSchemes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v3/repos/team/tool":
+			fmt.Fprint(rw, `{"name": "tool"}`)
+		case "/api/v3/repos/team/tool/releases":
+			fmt.Fprint(rw, `[
+  {"tag_name": "24.04.1"},
+  {"tag_name": "24.10"},
+  {"tag_name": "24.04"},
+  {"tag_name": "nightly"}
+]`)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	for _, tc := range []struct {
+		scheme deppkg.VersionScheme
+		format string
+	}{
+		{scheme: deppkg.Calver, format: "YY.0M.MICRO"},
+		{scheme: deppkg.Custom, format: `(?P<year>\d+)\.(?P<month>\d+)(\.(?P<micro>\d+))?`},
+	} {
+		t.Run(string(tc.scheme), func(t *testing.T) {
+			deps := []*deppkg.Dependency{
+				{
+					Name:    "tool",
+					Version: "24.04",
+					Scheme:  tc.scheme,
+					Format:  tc.format,
+					Upstream: map[string]string{
+						"flavour": "github",
+						"server":  server.URL,
+						"url":     "team/tool",
+					},
+				},
+			}
+
+			client, err := NewRemoteClient()
+			require.NoError(t, err)
+			updateInfos, err := client.CheckUpstreamVersions(deps)
+			require.NoError(t, err)
+			require.Len(t, updateInfos, 1)
+			require.True(t, updateInfos[0].UpdateAvailable)
+			require.Equal(t, "24.10", updateInfos[0].Latest.Version)
+		})
+	}
+}
+
+func TestCheckUpstreamVersionsDigests(t *testing.T) {
+	digest := func(c string) string { return "@sha256:" + strings.Repeat(c, 64) }
+
+	deps := []*deppkg.Dependency{
+		{
+			Name:     "policy",
+			Version:  "1.4.2" + digest("a"),
+			Scheme:   deppkg.Semver,
+			Policy:   &deppkg.UpdatePolicy{AutoUpgrade: deppkg.Minor},
+			Upstream: map[string]string{"flavour": "dummy", "latest": "2.0.0" + digest("b")},
+		},
+		{
+			Name:         "versions",
+			Versions:     []*deppkg.VersionEntry{{Version: "1.29.1" + digest("c")}, {Version: "1.30.1" + digest("d")}},
+			VersionsMode: deppkg.VersionsRolling,
+			Scheme:       deppkg.Semver,
+			Upstream:     map[string]string{"flavour": "dummy", "latest": "1.31.0" + digest("e")},
+		},
+	}
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+	updateInfos, err := client.CheckUpstreamVersions(deps)
+	require.NoError(t, err)
+	require.Len(t, updateInfos, 3)
+
+	// The dummy flavour ignores constraints, so no 1.x version is found
+	require.False(t, updateInfos[0].UpdateAvailable)
+	require.True(t, updateInfos[0].NotifyAvailable)
+
+	require.True(t, updateInfos[1].UpdateAvailable)
+	require.Equal(t, "1.31.0"+digest("e"), updateInfos[1].Latest.Version)
+}
+
+func TestCheckUpstreamVersionsCodenames(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	repository := strings.TrimPrefix(server.URL, "http://") + "/library/python"
+	for _, tag := range []string{"3.12.4-slim-bullseye", "3.12.4-slim-bookworm"} {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+
+		ref, err := name.ParseReference(repository + ":" + tag)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+	}
+
+	up := map[string]string{
+		"flavour":   "container",
+		"registry":  repository,
+		"variant":   "slim-bullseye",
+		"codenames": "bullseye,bookworm,trixie",
+	}
+	deps := []*deppkg.Dependency{
+		{Name: "bullseye", Version: "3.12.4-slim-bullseye", Scheme: deppkg.Semver, Upstream: up},
+		{Name: "bookworm", Version: "3.12.4-slim-bookworm", Scheme: deppkg.Semver, Upstream: up},
+	}
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+	updateInfos, err := client.CheckUpstreamVersions(deps)
+	require.NoError(t, err)
+	require.Len(t, updateInfos, 2)
+
+	// A newer codename of the same version is an update, although semver sorts
+	// bookworm before bullseye
+	require.True(t, updateInfos[0].UpdateAvailable)
+	require.Equal(t, "3.12.4-slim-bookworm", updateInfos[0].Latest.Version)
+
+	require.False(t, updateInfos[1].UpdateAvailable)
+}
+
+func TestUpgradeGroup(t *testing.T) {
+	server := helmRepoServer(t)
+
+	for _, tc := range []struct {
+		name     string
+		dummy    string
+		upgrades []string
+		expected string
+	}{
+		{
+			name:  "common version",
+			dummy: "1.5.1",
+			upgrades: []string{
+				"Upgraded dependency chart from version 1.5.0 to version 1.5.1",
+				"Upgraded dependency dummy from version 1.5.0 to version 1.5.1",
+				"Upgraded dependency docs from version 1.5.0 to version 1.5.1",
+			},
+			expected: "CHART: 1.5.1\nDUMMY: 1.5.1\nDOCS: 1.5.1",
+		},
+		{
+			// The chart has no version 1.5.2, and the dummy flavour has no 1.5.1
+			name:     "no common version",
+			dummy:    "1.5.2",
+			upgrades: []string{},
+			expected: "CHART: 1.5.0\nDUMMY: 1.5.0\nDOCS: 1.5.0",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.txt")
+
+			err := os.WriteFile(testFile, []byte("CHART: 1.5.0\nDUMMY: 1.5.0\nDOCS: 1.5.0"), 0o644)
+			require.NoError(t, err)
+
+			err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(`
+dependencies:
+  - name: chart
+    version: 1.5.0
+    group: release
+    upstream:
+      flavour: helm
+      repo: `+server.URL+`
+      chart: dependency-two
+    refPaths:
+    - path: test.txt
+      match: CHART
+  - name: dummy
+    version: 1.5.0
+    group: release
+    upstream:
+      flavour: dummy
+      latest: `+tc.dummy+`
+    refPaths:
+    - path: test.txt
+      match: DUMMY
+  - name: docs
+    version: 1.5.0
+    group: release
+    refPaths:
+    - path: test.txt
+      match: DOCS
+`), 0o644)
+			require.NoError(t, err)
+
+			client, err := NewRemoteClient()
+			require.NoError(t, err)
+
+			upgrades, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+			require.NoError(t, err)
+			require.Equal(t, tc.upgrades, upgrades)
+
+			got, err := os.ReadFile(testFile)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(got))
+		})
+	}
+}
+
+func TestUpgradeGroupLowering(t *testing.T) {
+	var index strings.Builder
+	index.WriteString("apiVersion: v1\nentries:\n")
+	for chart, versions := range map[string][]string{
+		"a": {"2.0.0", "1.5.0", "1.3.0", "1.1.0", "1.0.0"},
+		"b": {"2.0.0", "1.4.0", "1.2.0", "1.1.0", "1.0.0"},
+	} {
+		fmt.Fprintf(&index, "  %s:\n", chart)
+		for _, version := range versions {
+			fmt.Fprintf(&index, "  - apiVersion: v2\n    name: %s\n    version: %s\n", chart, version)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, index.String())
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+
+	err := os.WriteFile(testFile, []byte("A: 1.0.0\nB: 1.0.0"), 0o644)
+	require.NoError(t, err)
+
+	// 1.4.0, 1.3.0 and 1.2.0 are each missing from a chart, and 2.0.0 is
+	// excluded by the relative constraints
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(`
+dependencies:
+  - name: a
+    version: 1.0.0
+    group: charts
+    upstream:
+      flavour: helm
+      repo: `+server.URL+`
+      chart: a
+      constraints: same-major
+    refPaths:
+    - path: test.txt
+      match: "A:"
+  - name: b
+    version: 1.0.0
+    group: charts
+    upstream:
+      flavour: helm
+      repo: `+server.URL+`
+      chart: b
+      constraints: '< {{ .Major | add 1 }}.0.0'
+    refPaths:
+    - path: test.txt
+      match: "B:"
+`), 0o644)
+	require.NoError(t, err)
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	upgrades, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Upgraded dependency a from version 1.0.0 to version 1.1.0",
+		"Upgraded dependency b from version 1.0.0 to version 1.1.0",
+	}, upgrades)
+
+	got, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	require.Equal(t, "A: 1.1.0\nB: 1.1.0", string(got))
+}
+
+func TestUpgradeVersions(t *testing.T) {
+	var index strings.Builder
+	index.WriteString("apiVersion: v1\nentries:\n  kubernetes:\n")
+	for _, version := range []string{"1.32.0", "1.31.3", "1.31.2", "1.30.7", "1.30.6", "1.29.11", "1.29.10"} {
+		fmt.Fprintf(&index, "  - apiVersion: v2\n    name: kubernetes\n    version: %s\n", version)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, index.String())
+	}))
+	t.Cleanup(server.Close)
+
+	for _, tc := range []struct {
+		mode        string
+		sensitivity string
+		upgrades    []string
+		expected    string
+	}{
+		{
+			mode: "patch",
+			upgrades: []string{
+				"Upgraded dependency kubernetes from version 1.31.2 to version 1.31.3",
+				"Upgraded dependency kubernetes from version 1.30.6 to version 1.30.7",
+				"Upgraded dependency kubernetes from version 1.29.10 to version 1.29.11",
+			},
+			expected: "LATEST: 1.31.3\nMATRIX: [1.29.11, 1.30.7, 1.31.3]",
+		},
+		{
+			mode: "rolling",
+			upgrades: []string{
+				"Upgraded dependency kubernetes from version 1.31.2 to version 1.32.0",
+				"Upgraded dependency kubernetes from version 1.30.6 to version 1.31.3",
+				"Upgraded dependency kubernetes from version 1.29.10 to version 1.30.7",
+			},
+			expected: "LATEST: 1.32.0\nMATRIX: [1.30.7, 1.31.3, 1.32.0]",
+		},
+		{
+			// Patch versions are below the sensitivity
+			mode:        "patch",
+			sensitivity: "minor",
+			upgrades:    []string{},
+			expected:    "LATEST: 1.31.2\nMATRIX: [1.29.10, 1.30.6, 1.31.2]",
+		},
+	} {
+		t.Run(tc.mode+" "+tc.sensitivity, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.txt")
+
+			err := os.WriteFile(testFile, []byte("LATEST: 1.31.2\nMATRIX: [1.29.10, 1.30.6, 1.31.2]"), 0o644)
+			require.NoError(t, err)
+
+			err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(`
+dependencies:
+  - name: kubernetes
+    versionsMode: `+tc.mode+`
+    sensitivity: `+tc.sensitivity+`
+    versions:
+    - version: 1.29.10
+    - version: 1.30.6
+    - version: 1.31.2
+      refPaths:
+      - path: test.txt
+        match: LATEST
+    upstream:
+      flavour: helm
+      repo: `+server.URL+`
+      chart: kubernetes
+    refPaths:
+    - path: test.txt
+      match: MATRIX
+`), 0o644)
+			require.NoError(t, err)
+
+			client, err := NewRemoteClient()
+			require.NoError(t, err)
+
+			err = client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir)
+			require.NoError(t, err)
+
+			upgrades, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+			require.NoError(t, err)
+			require.Equal(t, tc.upgrades, upgrades)
+
+			got, err := os.ReadFile(testFile)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(got))
+
+			// The configuration is still in sync with the files
+			err = client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUpgradeArtifacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, "artifact at "+req.URL.Path)
+	}))
+	defer server.Close()
+
+	checksum := func(path string) string {
+		sum := sha256.Sum256([]byte("artifact at " + path))
+		return hex.EncodeToString(sum[:])
+	}
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+
+	err := os.WriteFile(testFile, []byte("VERSION: 0.0.1\nSHA256: "+checksum("/0.0.1/tool")), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(fmt.Sprintf(`
+dependencies:
+  - name: tool
+    version: 0.0.1
+    upstream:
+      flavour: dummy
+    artifacts:
+    - url: %s/{{ .Version }}/tool
+      sha256: %s
+      refPaths:
+      - path: test.txt
+        match: SHA256
+    refPaths:
+    - path: test.txt
+      match: VERSION
+`, server.URL, checksum("/0.0.1/tool"))), 0o644)
+	require.NoError(t, err)
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	ret, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Upgraded dependency tool from version 0.0.1 to version 1.0.0"}, ret)
+
+	got, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	require.Equal(t, "VERSION: 1.0.0\nSHA256: "+checksum("/1.0.0/tool"), string(got))
+
+	externalDeps, err := deppkg.FromFile(filepath.Join(dir, "dependencies.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, checksum("/1.0.0/tool"), externalDeps.Dependencies[0].Artifacts[0].SHA256)
+
+	require.NoError(t, client.LocalCheck(filepath.Join(dir, "dependencies.yaml"), dir))
+	require.NoError(t, client.VerifyArtifacts(filepath.Join(dir, "dependencies.yaml")))
+}
+
+func TestUpgradeArtifactsUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	checksum := strings.Repeat("a", 64)
+
+	err := os.WriteFile(testFile, []byte("VERSION: 0.0.1\nSHA256: "+checksum), 0o644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "dependencies.yaml"), []byte(fmt.Sprintf(`
+dependencies:
+  - name: tool
+    version: 0.0.1
+    upstream:
+      flavour: dummy
+    artifacts:
+    - url: %s/{{ .Version }}/tool
+      sha256: %s
+      refPaths:
+      - path: test.txt
+        match: SHA256
+    refPaths:
+    - path: test.txt
+      match: VERSION
+`, server.URL, checksum)), 0o644)
+	require.NoError(t, err)
+
+	client, err := NewRemoteClient()
+	require.NoError(t, err)
+
+	ret, err := client.Upgrade(filepath.Join(dir, "dependencies.yaml"), dir)
+	require.NoError(t, err)
+	require.Empty(t, ret)
+
+	got, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	require.Equal(t, "VERSION: 0.0.1\nSHA256: "+checksum, string(got))
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	deppkg "sigs.k8s.io/zeitgeist/dependency"
+)
+
+// resolveGroups makes the members of each group of dependencies move together:
+// they are only updated when every member can move to the same version, which
+// then replaces the version update of each member.
+//
+// Members without upstream follow the version of the group, so results gains
+// an entry for them when the group is updated.
+func (c *RemoteClient) resolveGroups(deps []*deppkg.Dependency, results []*deppkg.VersionUpdateInfo) error {
+	groups := map[string][]int{}
+	for i, dep := range deps {
+		if dep.Group != "" {
+			groups[dep.Group] = append(groups[dep.Group], i)
+		}
+	}
+
+	for _, group := range slices.Sorted(maps.Keys(groups)) {
+		if err := c.resolveGroup(group, deps, groups[group], results); err != nil {
+			return fmt.Errorf("group %s: %w", group, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *RemoteClient) resolveGroup(group string, deps []*deppkg.Dependency, members []int, results []*deppkg.VersionUpdateInfo) error {
+	first := deps[members[0]]
+	current := first.VersionOf(first.Version)
+
+	var candidate *deppkg.Version
+	var held *deppkg.Dependency
+	for _, i := range members {
+		if !sameVersion(deps[i].Version, first.Version) {
+			return fmt.Errorf("dependencies %s and %s are at different versions: %s and %s", first.Name, deps[i].Name, first.Version, deps[i].Version)
+		}
+
+		if results[i] == nil {
+			continue
+		}
+
+		if results[i].Hold != nil {
+			held = deps[i]
+		}
+
+		latest := results[i].Latest
+		if candidate == nil {
+			candidate = &latest
+			continue
+		}
+
+		moreRecent, err := candidate.MoreRecentThan(latest)
+		if err != nil {
+			return err
+		}
+		if moreRecent {
+			candidate = &latest
+		}
+	}
+
+	if candidate == nil {
+		return nil
+	}
+
+	updateAvailable, err := candidate.MoreRecentThan(current)
+	if err != nil {
+		return err
+	}
+
+	if updateAvailable && held != nil {
+		log.Infof("Not updating group %s, dependency %s is held until %s", group, held.Name, held.Hold.Until)
+		updateAvailable = false
+	}
+
+	if updateAvailable {
+		common, found, err := c.commonVersion(deps, members, results, *candidate)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			candidate = &common
+			updateAvailable, err = candidate.MoreRecentThan(current)
+			if err != nil {
+				return err
+			}
+		} else {
+			log.Infof("Not updating group %s, its dependencies have no version in common", group)
+			updateAvailable = false
+		}
+	}
+
+	for _, i := range members {
+		if !updateAvailable {
+			if results[i] != nil {
+				results[i].UpdateAvailable = false
+			}
+			continue
+		}
+
+		dep := deps[i]
+		latest := dep.VersionOf(formatVersion(dep.Version, candidate.Version))
+		if results[i] == nil {
+			results[i] = &deppkg.VersionUpdateInfo{
+				Name:    dep.Name,
+				Current: dep.VersionOf(dep.Version),
+			}
+		}
+		results[i].Latest = latest
+		results[i].UpdateAvailable = true
+	}
+
+	return nil
+}
+
+// commonVersion looks for the most recent version, at most candidate, that is
+// the latest version of every member of a group given their constraints, once
+// resolved as deps' upstreams are. It returns false if there is none.
+func (c *RemoteClient) commonVersion(deps []*deppkg.Dependency, members []int, results []*deppkg.VersionUpdateInfo, candidate deppkg.Version) (deppkg.Version, bool, error) {
+	// Each iteration either agrees on candidate, or lowers it to a version an
+	// upstream has, so this ends once candidate stops decreasing
+	for {
+		agreed := true
+
+		for _, i := range members {
+			dep := deps[i]
+			if results[i] == nil || sameVersion(results[i].Latest.Version, candidate.Version) {
+				continue
+			}
+
+			if dep.Scheme != deppkg.Semver {
+				return deppkg.Version{}, false, nil
+			}
+
+			up := make(map[string]string, len(dep.Upstream)+1)
+			for k, v := range dep.Upstream {
+				up[k] = v
+			}
+			up["constraints"] = mergeConstraints(dep.Upstream["constraints"], "<= "+strings.TrimPrefix(candidate.Version, "v"))
+
+			version, err := c.latestUpstreamVersion(dep, up)
+			if err != nil {
+				log.Debugf("No version of dependency %s up to %s: %v", dep.Name, candidate.Version, err)
+				return deppkg.Version{}, false, nil
+			}
+
+			if sameVersion(version, candidate.Version) {
+				continue
+			}
+
+			lower := dep.VersionOf(version)
+			moreRecent, err := lower.MoreRecentThan(candidate)
+			if err != nil {
+				return deppkg.Version{}, false, err
+			}
+			if moreRecent {
+				// The upstream doesn't support constraints
+				return deppkg.Version{}, false, nil
+			}
+
+			log.Debugf("Dependency %s has no version %s, trying %s", dep.Name, candidate.Version, version)
+			candidate = lower
+			agreed = false
+			break
+		}
+
+		if agreed {
+			return candidate, true, nil
+		}
+	}
+}
+
+// sameVersion returns whether two versions are the same, regardless of a
+// leading `v`.
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/blang/semver/v4"
+
+	deppkg "sigs.k8s.io/zeitgeist/dependency"
+)
+
+const (
+	// sameMajor constrains upstream versions to the major version of the dependency.
+	sameMajor = "same-major"
+	// sameMinor constrains upstream versions to the minor version of the dependency.
+	sameMinor = "same-minor"
+)
+
+// depReference matches references to other dependencies in upstream templates,
+// e.g. {{ dep "kubernetes" | majorMinor }}.
+var depReference = regexp.MustCompile(`\bdep\s+"([^"]+)"`)
+
+// ownTemplates are the upstream parameters rendered by the upstreams themselves,
+// for every version they find, e.g. versionTemplate: "{{ .Tag }}". They are
+// left as is.
+var ownTemplates = []string{"versionTemplate", "asset", "checksums"}
+
+// templateFuncs returns the functions available in upstream templates. `dep`
+// looks up the version of another dependency in versions.
+func templateFuncs(versions map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"add": func(a, b uint64) uint64 { return b + a },
+		"sub": func(a, b uint64) (uint64, error) {
+			if a > b {
+				return 0, fmt.Errorf("cannot subtract %d from %d", a, b)
+			}
+			return b - a, nil
+		},
+		"dep": func(name string) (string, error) {
+			version, ok := versions[name]
+			if !ok {
+				return "", fmt.Errorf("unknown dependency %q", name)
+			}
+			return version, nil
+		},
+		"major": func(version string) (string, error) {
+			v, err := semver.ParseTolerant(version)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d", v.Major), nil
+		},
+		"majorMinor": func(version string) (string, error) {
+			v, err := semver.ParseTolerant(version)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d.%d", v.Major, v.Minor), nil
+		},
+	}
+}
+
+// renderTemplate renders an upstream template. The fields of the current
+// version, e.g. {{ .Major }}, are available when it is semver.
+func renderTemplate(text, current string, funcs template.FuncMap) (string, error) {
+	tmpl, err := template.New("upstream").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing template %q: %w", text, err)
+	}
+
+	var data *semver.Version
+	if v, err := semver.ParseTolerant(current); err == nil {
+		data = &v
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("rendering template %q: %w", text, err)
+	}
+
+	return rendered.String(), nil
+}
+
+// relativeConstraints resolves constraints relative to the current version of
+// a dependency: `same-major` and `same-minor`, or templates such as
+// "< {{ .Major | add 1 }}.0.0". Other constraints are returned as is.
+func relativeConstraints(constraints, current string, funcs template.FuncMap) (string, error) {
+	switch strings.TrimSpace(constraints) {
+	case sameMajor, sameMinor:
+	default:
+		if !strings.Contains(constraints, "{{") {
+			return constraints, nil
+		}
+		return renderTemplate(constraints, current, funcs)
+	}
+
+	v, err := semver.ParseTolerant(current)
+	if err != nil {
+		return "", fmt.Errorf("constraints %q are relative to the current version, which is not semver: %w", constraints, err)
+	}
+
+	if strings.TrimSpace(constraints) == sameMajor {
+		return fmt.Sprintf(">= %d.0.0-0 < %d.0.0-0", v.Major, v.Major+1), nil
+	}
+
+	return fmt.Sprintf(">= %d.%d.0-0 < %d.%d.0-0", v.Major, v.Minor, v.Major, v.Minor+1), nil
+}
+
+// resolveUpstream renders the templates in the upstream parameters of a
+// dependency, except ownTemplates, given the versions of all dependencies.
+func resolveUpstream(dep *deppkg.Dependency, versions map[string]string) (map[string]string, error) {
+	funcs := templateFuncs(versions)
+	current, _ := deppkg.SplitDigest(dep.Version)
+
+	resolved := make(map[string]string, len(dep.Upstream))
+	for key, value := range dep.Upstream {
+		var err error
+		if key == "constraints" {
+			value, err = relativeConstraints(value, current, funcs)
+		} else if strings.Contains(value, "{{") && !slices.Contains(ownTemplates, key) {
+			value, err = renderTemplate(value, current, funcs)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("upstream %s: %w", key, err)
+		}
+
+		resolved[key] = value
+	}
+
+	return resolved, nil
+}
+
+// evaluationOrder returns the indexes of dependencies in the order they should
+// be checked: dependencies referred to by the upstream of another one come
+// first, and the order of the configuration file is kept otherwise.
+func evaluationOrder(deps []*deppkg.Dependency) ([]int, error) {
+	indexes := make(map[string]int, len(deps))
+	for i, dep := range deps {
+		indexes[dep.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(deps))
+	order := make([]int, 0, len(deps))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("circular reference between upstreams of dependency %s", deps[i].Name)
+		}
+
+		state[i] = visiting
+		for _, key := range slices.Sorted(maps.Keys(deps[i].Upstream)) {
+			if slices.Contains(ownTemplates, key) {
+				continue
+			}
+			for _, match := range depReference.FindAllStringSubmatch(deps[i].Upstream[key], -1) {
+				j, ok := indexes[match[1]]
+				if !ok {
+					return fmt.Errorf("dependency %s refers to unknown dependency %s", deps[i].Name, match[1])
+				}
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = visited
+
+		order = append(order, i)
+		return nil
+	}
+
+	for i := range deps {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependency
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/blang/semver/v4"
+	log "github.com/sirupsen/logrus"
+
+	deppkg "sigs.k8s.io/zeitgeist/dependency"
+)
+
+// checkUpstreamVersionEntries checks the upstream versions of a dependency with
+// several versions, returning an update per entry, newest entry first.
+//
+// Each entry is bumped to the latest patch version of its minor version. In
+// rolling mode, a new minor version also rolls the window forward: the newest
+// entry moves to the new minor version, each other entry to the minor version
+// of the next newer one, and the oldest minor version is dropped.
+func (c *RemoteClient) checkUpstreamVersionEntries(dep *deppkg.Dependency) ([]deppkg.VersionUpdateInfo, error) {
+	entries := newestFirst(dep.Versions)
+
+	minors := make([]semver.Version, 0, len(entries))
+	for _, entry := range entries {
+		version, _ := deppkg.SplitDigest(entry.Version)
+		v, err := semver.ParseTolerant(version)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", dep.Name, err)
+		}
+		minors = append(minors, v)
+	}
+
+	if dep.VersionsMode == deppkg.VersionsRolling {
+		latest, err := c.latestUpstreamVersion(dep, dep.Upstream)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", dep.Name, err)
+		}
+
+		latest, _ = deppkg.SplitDigest(latest)
+		v, err := semver.ParseTolerant(latest)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", dep.Name, err)
+		}
+
+		if v.Major > minors[0].Major || (v.Major == minors[0].Major && v.Minor > minors[0].Minor) {
+			log.Debugf("New minor version %d.%d of dependency %s, rolling its versions forward", v.Major, v.Minor, dep.Name)
+			minors = append([]semver.Version{v}, minors[:len(minors)-1]...)
+		}
+	}
+
+	versionUpdates := make([]deppkg.VersionUpdateInfo, 0, len(entries))
+	for i, entry := range entries {
+		current := dep.VersionOf(entry.Version)
+
+		latest, err := c.latestPatchVersion(dep, minors[i])
+		if err != nil {
+			log.Debugf("No version of dependency %s within %d.%d: %v", dep.Name, minors[i].Major, minors[i].Minor, err)
+			latest = entry.Version
+		}
+
+		latestVersion := dep.VersionOf(formatVersion(entry.Version, latest))
+
+		updateAvailable, err := moreSensitivelyRecent(dep, latestVersion, current, dep.Sensitivity)
+		if err != nil {
+			return nil, fmt.Errorf("comparing dependency %s: %w", dep.Name, err)
+		}
+
+		versionUpdate := deppkg.VersionUpdateInfo{
+			Name:            dep.Name,
+			Current:         current,
+			Latest:          latestVersion,
+			UpdateAvailable: updateAvailable,
+			Entry:           entry,
+		}
+		applyHold(dep, &versionUpdate)
+
+		versionUpdates = append(versionUpdates, versionUpdate)
+	}
+
+	return versionUpdates, nil
+}
+
+// latestPatchVersion retrieves the latest upstream version of a dependency with
+// the same minor version as the given one.
+func (c *RemoteClient) latestPatchVersion(dep *deppkg.Dependency, minor semver.Version) (string, error) {
+	up := make(map[string]string, len(dep.Upstream)+1)
+	for k, v := range dep.Upstream {
+		up[k] = v
+	}
+	up["constraints"] = mergeConstraints(
+		dep.Upstream["constraints"],
+		fmt.Sprintf(">= %d.%d.0-0 < %d.%d.0-0", minor.Major, minor.Minor, minor.Major, minor.Minor+1),
+	)
+
+	version, err := c.latestUpstreamVersion(dep, up)
+	if err != nil {
+		return "", err
+	}
+
+	// Not every flavour supports constraints, so double-check the result
+	tag, _ := deppkg.SplitDigest(version)
+	v, err := semver.ParseTolerant(tag)
+	if err != nil {
+		return "", err
+	}
+	if v.Major != minor.Major || v.Minor != minor.Minor {
+		return "", fmt.Errorf("upstream returned version %s outside of %d.%d", version, minor.Major, minor.Minor)
+	}
+
+	return version, nil
+}
+
+// newestFirst returns the entries sorted by version, newest first. Updating
+// entries in this order keeps references to several versions (e.g. a matrix)
+// consistent when versions roll forward.
+func newestFirst(entries []*deppkg.VersionEntry) []*deppkg.VersionEntry {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b *deppkg.VersionEntry) int {
+		aTag, _ := deppkg.SplitDigest(a.Version)
+		bTag, _ := deppkg.SplitDigest(b.Version)
+		aVersion, aErr := semver.ParseTolerant(aTag)
+		bVersion, bErr := semver.ParseTolerant(bTag)
+		if aErr != nil || bErr != nil {
+			return 0
+		}
+		return bVersion.Compare(aVersion)
+	})
+
+	return sorted
+}
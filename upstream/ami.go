@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -91,7 +92,21 @@ func (upstream AMI) LatestVersion() (string, error) {
 		return "", err
 	}
 
-	images := result.Images
+	images := make([]types.Image, 0, len(result.Images))
+	for _, image := range result.Images {
+		if upstream.Ignores(aws.ToString(image.ImageId)) {
+			log.Debugf("Skipping ignored AMI: %s", aws.ToString(image.ImageId))
+			continue
+		}
+
+		created, err := time.Parse(time.RFC3339, aws.ToString(image.CreationDate))
+		if err == nil && upstream.tooRecent(created) {
+			log.Debugf("Skipping AMI created less than %s ago: %s", upstream.MinReleaseAge, aws.ToString(image.ImageId))
+			continue
+		}
+
+		images = append(images, image)
+	}
 
 	// Sort images by creation time, so we can return the latest
 	sort.Slice(images, func(i, j int) bool { return *images[i].CreationDate > *images[j].CreationDate })
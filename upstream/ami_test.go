@@ -19,6 +19,7 @@ package upstream
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -75,6 +76,34 @@ func TestGetAMI(t *testing.T) {
 			Expected:      "ami-honk",
 			ExpectedError: false,
 		},
+		{
+			Name: "AMI more recent than the minimum release age is skipped",
+			Input: AMI{
+				Base:  Base{Filter: Filter{MinReleaseAge: 72 * time.Hour}},
+				Owner: "amazon",
+				Name:  "amazon-eks-node-1.13-*",
+			},
+			Client: func(_ *testing.T) mockEc2Api {
+				return mockEc2Api(func(_ context.Context, _ *ec2.DescribeImagesInput, _ ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error) {
+					return &ec2.DescribeImagesOutput{
+						Images: []types.Image{
+							{
+								CreationDate: aws.String("2019-05-10T13:17:12.000Z"),
+								ImageId:      aws.String("ami-honk"),
+								Name:         aws.String("amazon-eks-node-1.13-honk"),
+							},
+							{
+								CreationDate: aws.String(time.Now().UTC().Format(time.RFC3339)),
+								ImageId:      aws.String("ami-123newimage"),
+								Name:         aws.String("amazon-eks-node-1.13-new"),
+							},
+						},
+					}, nil
+				})
+			},
+			Expected:      "ami-honk",
+			ExpectedError: false,
+		},
 		{
 			Name: "AMI does not exist",
 			Input: AMI{
@@ -0,0 +1,88 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Commits configures how commit SHAs are reported, when tracking a branch.
+type Commits struct {
+	// Optional: number of characters of commit SHAs to report, e.g. 7 for
+	// short SHAs. Defaults to the full SHA.
+	ShortSHA string
+}
+
+// CommitCompanion is the companion value of the commit a release tag points to.
+const CommitCompanion = "commit"
+
+// commitSHA matches full or abbreviated commit SHAs.
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{4,64}$`)
+
+// shortened returns the SHA abbreviated to ShortSHA characters.
+func (c *Commits) shortened(sha string) (string, error) {
+	if c.ShortSHA == "" {
+		return sha, nil
+	}
+
+	length, err := strconv.Atoi(c.ShortSHA)
+	if err != nil || length < 4 {
+		return "", fmt.Errorf("invalid shortSHA %q: must be a number of characters, at least 4", c.ShortSHA)
+	}
+
+	if len(sha) > length {
+		return sha[:length], nil
+	}
+
+	return sha, nil
+}
+
+// newerCommit returns the head of a branch, or the current commit if the head
+// isn't more recent, e.g. when a branch was reset. commitDate returns the date
+// of a commit. If the date of the current commit can't be found, the head is
+// returned.
+func (c *Commits) newerCommit(current, head string, commitDate func(sha string) (time.Time, error)) (string, error) {
+	// Shortening the head might yield the current commit
+	if current != "" && strings.HasPrefix(head, current) {
+		return current, nil
+	}
+
+	if commitSHA.MatchString(current) {
+		currentDate, err := commitDate(current)
+		if err != nil {
+			log.Debugf("Cannot find the date of the current commit %s, using the head of the branch: %v", current, err)
+		} else {
+			headDate, err := commitDate(head)
+			if err != nil {
+				return "", fmt.Errorf("retrieving commit %s: %w", head, err)
+			}
+
+			if !headDate.After(currentDate) {
+				log.Debugf("Head %s of the branch is not more recent than the current commit %s", head, current)
+				return current, nil
+			}
+		}
+	}
+
+	return c.shortened(head)
+}
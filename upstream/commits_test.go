@@ -0,0 +1,80 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upstream
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitsShortened(t *testing.T) {
+	sha := "0123456789abcdef0123456789abcdef01234567"
+
+	for _, tc := range []struct {
+		shortSHA, expected string
+		shouldErr          bool
+	}{
+		{shortSHA: "", expected: sha},
+		{shortSHA: "7", expected: "0123456"},
+		{shortSHA: "64", expected: sha},
+		{shortSHA: "3", shouldErr: true},
+		{shortSHA: "true", shouldErr: true},
+	} {
+		c := Commits{ShortSHA: tc.shortSHA}
+
+		got, err := c.shortened(sha)
+		if tc.shouldErr {
+			require.Error(t, err, tc.shortSHA)
+			continue
+		}
+		require.NoError(t, err, tc.shortSHA)
+		require.Equal(t, tc.expected, got, tc.shortSHA)
+	}
+}
+
+func TestCommitsNewerCommit(t *testing.T) {
+	dates := map[string]time.Time{
+		"1111111111111111111111111111111111111111": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"2222222222222222222222222222222222222222": time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	commitDate := func(sha string) (time.Time, error) {
+		for full, date := range dates {
+			if full[:len(sha)] == sha {
+				return date, nil
+			}
+		}
+		return time.Time{}, errors.New("not found")
+	}
+
+	c := Commits{ShortSHA: "7"}
+	for _, tc := range []struct {
+		name, current, head, expected string
+	}{
+		{name: "newer head", current: "1111111", head: "2222222222222222222222222222222222222222", expected: "2222222"},
+		{name: "same commit", current: "2222222", head: "2222222222222222222222222222222222222222", expected: "2222222"},
+		{name: "branch reset", current: "2222222", head: "1111111111111111111111111111111111111111", expected: "2222222"},
+		{name: "unknown current commit", current: "3333333", head: "1111111111111111111111111111111111111111", expected: "1111111"},
+		{name: "no current commit", current: "", head: "1111111111111111111111111111111111111111", expected: "1111111"},
+	} {
+		got, err := c.newerCommit(tc.current, tc.head, commitDate)
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.expected, got, tc.name)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upstream
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	blang "github.com/blang/semver/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// parseConstraints parses semver constraints, shared by all upstreams.
+//
+// Ranges are parsed with blang/semver first, e.g. ">= 1.0.0 < 2.0.0", so that
+// existing constraints keep their meaning. Otherwise, npm-style constraints are
+// parsed with Masterminds/semver, e.g. "^1.4", "~2.3.0" or "1.x". These only
+// match pre-releases if they include one themselves, or includePrereleases is set.
+func parseConstraints(constraints string, includePrereleases bool) (blang.Range, error) {
+	expectedRange, err := blang.ParseRange(constraints)
+	if err == nil {
+		return expectedRange, nil
+	}
+
+	mastermindsConstraints, mastermindsErr := semver.NewConstraint(constraints)
+	if mastermindsErr != nil {
+		return nil, fmt.Errorf("parsing constraints %q: %w", constraints, mastermindsErr)
+	}
+
+	log.Debugf("Parsed constraints %q as npm-style constraints", constraints)
+	mastermindsConstraints.IncludePrerelease = includePrereleases
+
+	return func(version blang.Version) bool {
+		parsed, err := semver.NewVersion(version.String())
+		if err != nil {
+			return false
+		}
+
+		return mastermindsConstraints.Check(parsed)
+	}, nil
+}
+
+// constraintsRange parses the semver constraints of an upstream, matching
+// pre-releases when they are included.
+func (f *Filter) constraintsRange(constraints string) (blang.Range, error) {
+	return parseConstraints(constraints, f.Prereleases == PrereleasesInclude)
+}
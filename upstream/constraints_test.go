@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upstream
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		constraints string
+		matching    []string
+		notMatching []string
+	}{
+		{">= 1.0.0 < 2.0.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.0", "2.0.0"}},
+		{"<1.0.0 || >=3.0.0", []string{"0.1.0", "3.1.0"}, []string{"1.0.0", "2.5.0"}},
+		{"^1.4", []string{"1.4.0", "1.9.3"}, []string{"1.3.9", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"~2.3.0", []string{"2.3.0", "2.3.7"}, []string{"2.4.0", "2.2.9"}},
+		{"1.x", []string{"1.0.0", "1.99.0"}, []string{"2.0.0", "0.9.0"}},
+		{"1.28.x", []string{"1.28.0", "1.28.5"}, []string{"1.29.0"}},
+		{"^1.4 < 1.5.0-0", []string{"1.4.2"}, []string{"1.5.0", "2.0.0"}},
+		{"~2.3, != 2.3.4", []string{"2.3.3"}, []string{"2.3.4", "2.4.0"}},
+	} {
+		expectedRange, err := parseConstraints(tc.constraints, false)
+		require.NoError(t, err, tc.constraints)
+
+		for _, version := range tc.matching {
+			require.True(t, expectedRange(semver.MustParse(version)), "%s should match %s", version, tc.constraints)
+		}
+
+		for _, version := range tc.notMatching {
+			require.False(t, expectedRange(semver.MustParse(version)), "%s should not match %s", version, tc.constraints)
+		}
+	}
+
+	for _, invalid := range []string{"foo", "^^1", ">= 1.0.0 < bar"} {
+		_, err := parseConstraints(invalid, false)
+		require.Error(t, err, invalid)
+	}
+}
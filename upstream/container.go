@@ -19,9 +19,13 @@ package upstream
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/blang/semver/v4"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/zeitgeist/pkg/container"
@@ -35,24 +39,105 @@ type Container struct {
 	// Optional: semver constraints, e.g. < 2.0.0
 	// Will have no effect if the dependency does not follow Semver
 	Constraints string
+	// Optional: extract versions from prefixed or decorated tags
+	Tags `mapstructure:",squash"`
+	// Optional: `pin` to return the latest tag along with its digest, e.g.
+	// 1.2.3@sha256:..., for images pinned as repo:tag@digest
+	Digest string
+	// Optional: floating tag to track by digest alone, e.g. stable. The version
+	// is then the digest of the tag, which requires the random scheme.
+	Tag string
+	// Optional: comma-separated platforms that tags must be available for,
+	// e.g. linux/amd64,linux/arm64
+	Platforms string
+	// Optional: suffix of the tags to stay on, e.g. alpine for nginx:1.27.0-alpine
+	// or slim-bookworm for python:3.12.4-slim-bookworm
+	Variant string
+	// Optional: comma-separated OS codenames, oldest first, e.g. bullseye,bookworm,trixie.
+	// The codename ending the Variant may then move to a newer one.
+	Codenames string
+	// Optional: name of the credentials to use in the registry credentials file,
+	// instead of the credentials of the registry host
+	Auth string
+
+	// AWSConfig is the AWS configuration to authenticate to ECR registries
+	AWSConfig *aws.Config
 }
 
+// DigestPin pins the tags returned by the container upstream to their digest.
+const DigestPin = "pin"
+
 // LatestVersion returns the latest tag for the given repository
 // (depending on the Constraints if set).
 func (upstream Container) LatestVersion() (string, error) {
 	log.Debug("Using Container flavour")
+
+	if upstream.Digest != "" && upstream.Digest != DigestPin {
+		return "", fmt.Errorf("invalid container upstream: unknown digest mode %q, only %q is supported", upstream.Digest, DigestPin)
+	}
+
+	if _, err := upstream.requiredPlatforms(); err != nil {
+		return "", err
+	}
+
+	if upstream.Variant != "" {
+		if upstream.TagPattern != "" {
+			return "", errors.New("invalid container upstream: variant and tagPattern cannot be combined")
+		}
+
+		// Stay on tags of the variant, e.g. 1.27.0-alpine
+		if upstream.VersionTemplate == "" {
+			upstream.VersionTemplate = "{{ .Tag }}"
+		}
+	}
+
+	if upstream.Tag != "" {
+		return floatingTagDigest(&upstream)
+	}
+
 	return highestSemanticImageTag(&upstream)
 }
 
-func highestSemanticImageTag(upstream *Container) (string, error) {
+// client returns a container client using the credentials of the upstream.
+func (upstream *Container) client() *container.Container {
 	client := container.New()
+	client.CredentialsName = upstream.Auth
+	client.AWSConfig = upstream.AWSConfig
+
+	return client
+}
+
+// floatingTagDigest returns the digest a floating tag currently points to.
+func floatingTagDigest(upstream *Container) (string, error) {
+	client := upstream.client()
+
+	ref := upstream.Registry + ":" + upstream.Tag
+	missing, err := upstream.missingPlatforms(client, ref)
+	if err != nil {
+		return "", err
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s is not available for platforms %s", ref, strings.Join(missing, ", "))
+	}
+
+	log.Debugf("Retrieving digest of %s...", ref)
+	digest, err := client.Digest(ref)
+	if err != nil {
+		return "", fmt.Errorf("retrieving digest of %s: %w", ref, err)
+	}
+
+	return digest, nil
+}
+
+func highestSemanticImageTag(upstream *Container) (string, error) {
+	client := upstream.client()
 
 	semverConstraints := upstream.Constraints
 	if semverConstraints == "" {
 		// If no range is passed, just use the broadest possible range
 		semverConstraints = DefaultSemVerConstraints
 	}
-	expectedRange, err := semver.ParseRange(semverConstraints)
+	expectedRange, err := upstream.constraintsRange(semverConstraints)
 	if err != nil {
 		return "", fmt.Errorf("invalid semver constraints range: %v: %w", upstream.Constraints, err)
 	}
@@ -64,6 +149,223 @@ func highestSemanticImageTag(upstream *Container) (string, error) {
 	}
 	log.Debugf("Found %d tags for %s...", len(tags), upstream.Registry)
 
+	var versions []string
+	var tagOf map[string]string
+	if upstream.Variant != "" {
+		versions, tagOf, err = upstream.variantTags(tags)
+	} else {
+		versions, tagOf, err = upstream.extract(tags)
+	}
+	if err != nil {
+		return "", err
+	}
+	upstream.MoreRecent = upstream.comparingOutputs(upstream.MoreRecent, tagOf)
+
+	var candidates []string
+	if upstream.MoreRecent != nil {
+		if upstream.Constraints != "" {
+			log.Debugf("Ignoring semver constraints (%s) for a non-semver scheme", upstream.Constraints)
+		}
+		candidates = upstream.byRecency(versions)
+	} else {
+		candidates = semverSortedTags(versions, expectedRange, upstream.Constraints)
+	}
+
+	for _, version := range candidates {
+		if upstream.Ignores(version) {
+			log.Debugf("Skipping ignored tag: %s", version)
+			continue
+		}
+
+		if upstream.skipsPrerelease(version) {
+			log.Debugf("Skipping pre-release tag: %s", version)
+			continue
+		}
+
+		tag := version
+		if original, ok := tagOf[version]; ok {
+			tag = original
+		}
+
+		if upstream.MinReleaseAge > 0 {
+			created, err := client.Created(upstream.Registry + ":" + tag)
+			if err != nil {
+				log.Debugf("Cannot retrieve creation time of %s:%s, ignoring minimum release age: %v", upstream.Registry, tag, err)
+			} else if upstream.tooRecent(created) {
+				log.Debugf("Skipping tag created less than %s ago: %s", upstream.MinReleaseAge, tag)
+				continue
+			}
+		}
+
+		missing, err := upstream.missingPlatforms(client, upstream.Registry+":"+tag)
+		if err != nil {
+			return "", err
+		}
+		if len(missing) > 0 {
+			log.Infof("Skipping tag %s:%s, not available for platforms %s", upstream.Registry, tag, strings.Join(missing, ", "))
+			continue
+		}
+
+		log.Debugf("Found latest matching tag: %s", tag)
+
+		output, err := upstream.output(version, tagOf)
+		if err != nil || upstream.Digest != DigestPin {
+			return output, err
+		}
+
+		digest, err := client.Digest(upstream.Registry + ":" + tag)
+		if err != nil {
+			return "", fmt.Errorf("retrieving digest of %s:%s: %w", upstream.Registry, tag, err)
+		}
+
+		return output + "@" + digest, nil
+	}
+
+	return "", ErrNoVersion
+}
+
+// variantSuffixes returns the suffixes of the tags of the variant, newest
+// codename first when codenames are set.
+func (upstream *Container) variantSuffixes() ([]string, error) {
+	if upstream.Codenames == "" {
+		return []string{upstream.Variant}, nil
+	}
+
+	codenames := strings.Split(upstream.Codenames, ",")
+	for i := range codenames {
+		codenames[i] = strings.TrimSpace(codenames[i])
+	}
+
+	base, current := "", upstream.Variant
+	if i := strings.LastIndex(upstream.Variant, "-"); i >= 0 {
+		base, current = upstream.Variant[:i+1], upstream.Variant[i+1:]
+	}
+
+	oldest := slices.Index(codenames, current)
+	if oldest < 0 {
+		return nil, fmt.Errorf("invalid container upstream: variant %s doesn't end with one of the codenames %s", upstream.Variant, upstream.Codenames)
+	}
+
+	suffixes := make([]string, 0, len(codenames)-oldest)
+	for i := len(codenames) - 1; i >= oldest; i-- {
+		suffixes = append(suffixes, base+codenames[i])
+	}
+
+	return suffixes, nil
+}
+
+// variantTags keeps the tags of the variant, e.g. 1.27.0-alpine, and returns
+// their versions along with the tag of each version. When codenames are set,
+// the tag with the newest codename is kept for each version.
+func (upstream *Container) variantTags(tags []string) ([]string, map[string]string, error) {
+	suffixes, err := upstream.variantSuffixes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	versions := make([]string, 0, len(tags))
+	tagOf := make(map[string]string, len(tags))
+	rank := make(map[string]int, len(tags))
+	for _, tag := range tags {
+		for i, suffix := range suffixes {
+			// Tags of other variants ending with the same suffix, e.g.
+			// 1.27.0-perl-alpine for alpine, are skipped
+			version, found := strings.CutSuffix(tag, "-"+suffix)
+			if !found || version == "" || strings.Contains(version, "-") {
+				continue
+			}
+
+			if previous, ok := rank[version]; !ok {
+				versions = append(versions, version)
+			} else if previous < i {
+				break
+			}
+
+			tagOf[version] = tag
+			rank[version] = i
+			break
+		}
+	}
+
+	log.Debugf("Found %d tags of variant %s", len(versions), upstream.Variant)
+
+	return versions, tagOf, nil
+}
+
+// SplitVariant splits a tag of the variant into its version and the rank of
+// its codename, the newest codename having the highest rank. ok is false when
+// no codenames are set, or the tag doesn't belong to the variant.
+func (upstream *Container) SplitVariant(tag string) (version string, rank int, ok bool) {
+	if upstream.Codenames == "" {
+		return "", 0, false
+	}
+
+	suffixes, err := upstream.variantSuffixes()
+	if err != nil {
+		return "", 0, false
+	}
+
+	for i, suffix := range suffixes {
+		if version, found := strings.CutSuffix(tag, "-"+suffix); found && version != "" {
+			return version, len(suffixes) - i, true
+		}
+	}
+
+	return "", 0, false
+}
+
+// requiredPlatforms parses the platforms tags must be available for.
+func (upstream *Container) requiredPlatforms() ([]*v1.Platform, error) {
+	var platforms []*v1.Platform
+	for _, p := range strings.Split(upstream.Platforms, ",") {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+
+		platform, err := v1.ParsePlatform(strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("invalid container upstream platform %q: %w", p, err)
+		}
+		platforms = append(platforms, platform)
+	}
+
+	return platforms, nil
+}
+
+// missingPlatforms returns the required platforms an image is not available for.
+func (upstream *Container) missingPlatforms(client *container.Container, ref string) ([]string, error) {
+	required, err := upstream.requiredPlatforms()
+	if err != nil || len(required) == 0 {
+		return nil, err
+	}
+
+	log.Debugf("Retrieving platforms of %s...", ref)
+	available, err := client.Platforms(ref)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving platforms of %s: %w", ref, err)
+	}
+
+	var missing []string
+	for _, platform := range required {
+		found := false
+		for _, a := range available {
+			parsed, err := v1.ParsePlatform(a)
+			if err == nil && parsed.Satisfies(*platform) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			missing = append(missing, platform.String())
+		}
+	}
+
+	return missing, nil
+}
+
+// semverSortedTags returns the tags matching the semver constraints, highest first.
+func semverSortedTags(tags []string, expectedRange semver.Range, constraints string) []string {
 	// parse semvers first so we can safely sort
 	type semverWithOrig struct {
 		orig   string         // original tag string
@@ -94,15 +396,14 @@ func highestSemanticImageTag(upstream *Container) (string, error) {
 		return versions[j].parsed.LT(versions[i].parsed)
 	})
 
-	// find first version matching constraints
+	sorted := make([]string, 0, len(versions))
 	for _, version := range versions {
 		if !expectedRange(version.parsed) {
-			log.Debugf("Skipping release not matching range constraints (%s): %s", upstream.Constraints, version.parsed.String())
+			log.Debugf("Skipping release not matching range constraints (%s): %s", constraints, version.parsed.String())
 			continue
 		}
-		log.Debugf("Found latest matching tag: %s", version.orig)
-		return version.orig, nil
+		sorted = append(sorted, version.orig)
 	}
 
-	return "", errors.New("no potential tag found")
+	return sorted
 }
@@ -17,8 +17,20 @@ limitations under the License.
 package upstream
 
 import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
 	"go.yaml.in/yaml/v3"
 )
 
@@ -36,3 +48,156 @@ func TestUnserialiseContainer(t *testing.T) {
 		}
 	}
 }
+
+func TestContainerDigestLocal(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	repository := strings.TrimPrefix(server.URL, "http://") + "/honk/honk"
+	digests := map[string]string{}
+	for _, tag := range []string{"1.0.0", "1.1.0", "stable"} {
+		index, err := random.Index(64, 1, 2)
+		require.NoError(t, err)
+
+		ref, err := name.ParseReference(repository + ":" + tag)
+		require.NoError(t, err)
+		require.NoError(t, remote.WriteIndex(ref, index))
+
+		digest, err := index.Digest()
+		require.NoError(t, err)
+		digests[tag] = digest.String()
+	}
+
+	latest, err := Container{Registry: repository}.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "1.1.0", latest)
+
+	latest, err = Container{Registry: repository, Digest: DigestPin}.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "1.1.0@"+digests["1.1.0"], latest)
+
+	latest, err = Container{Registry: repository, Tag: "stable"}.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, digests["stable"], latest)
+
+	_, err = Container{Registry: repository, Digest: "always"}.LatestVersion()
+	require.Error(t, err)
+}
+
+func TestContainerPlatformsLocal(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	repository := strings.TrimPrefix(server.URL, "http://") + "/honk/honk"
+	for tag, platforms := range map[string][]v1.Platform{
+		"1.0.0": {{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64", Variant: "v8"}},
+		"1.1.0": {{OS: "linux", Architecture: "amd64"}},
+	} {
+		index := v1.ImageIndex(empty.Index)
+		for _, platform := range platforms {
+			img, err := random.Image(64, 1)
+			require.NoError(t, err)
+
+			index = mutate.AppendManifests(index, mutate.IndexAddendum{
+				Add:        img,
+				Descriptor: v1.Descriptor{Platform: &platform},
+			})
+		}
+
+		ref, err := name.ParseReference(repository + ":" + tag)
+		require.NoError(t, err)
+		require.NoError(t, remote.WriteIndex(ref, index))
+	}
+
+	for platforms, expected := range map[string]string{
+		"":                          "1.1.0",
+		"linux/amd64":               "1.1.0",
+		"linux/amd64, linux/arm64":  "1.0.0",
+		"linux/arm64/v8":            "1.0.0",
+		"linux/amd64,linux/ppc64le": "",
+	} {
+		latest, err := Container{Registry: repository, Platforms: platforms}.LatestVersion()
+		if expected == "" {
+			require.Error(t, err, platforms)
+			continue
+		}
+
+		require.NoError(t, err, platforms)
+		require.Equal(t, expected, latest, platforms)
+	}
+
+	_, err := Container{Registry: repository, Tag: "1.1.0", Platforms: "linux/arm64"}.LatestVersion()
+	require.Error(t, err)
+}
+
+func TestContainerVariantTags(t *testing.T) {
+	tags := []string{
+		"1.27.0", "1.27.0-alpine", "1.27.1", "1.28.0-perl-alpine", "1.26.2-alpine",
+		"3.12.4-slim-bullseye", "3.12.4-slim-bookworm", "3.12.5-slim-bullseye", "3.13.0-slim-trixie", "3.13.0-slim-buster",
+	}
+
+	for _, tc := range []struct {
+		variant, codenames string
+		expectedVersions   []string
+		expectedTags       []string
+		shouldErr          bool
+	}{
+		{
+			variant:          "alpine",
+			expectedVersions: []string{"1.27.0", "1.26.2"},
+			expectedTags:     []string{"1.27.0-alpine", "1.26.2-alpine"},
+		},
+		{
+			variant:          "slim-bullseye",
+			expectedVersions: []string{"3.12.4", "3.12.5"},
+			expectedTags:     []string{"3.12.4-slim-bullseye", "3.12.5-slim-bullseye"},
+		},
+		{
+			variant:          "slim-bullseye",
+			codenames:        "buster,bullseye,bookworm,trixie",
+			expectedVersions: []string{"3.12.4", "3.12.5", "3.13.0"},
+			expectedTags:     []string{"3.12.4-slim-bookworm", "3.12.5-slim-bullseye", "3.13.0-slim-trixie"},
+		},
+		{
+			variant:   "slim-bookworm",
+			codenames: "buster,bullseye",
+			shouldErr: true,
+		},
+	} {
+		upstream := Container{Variant: tc.variant, Codenames: tc.codenames}
+
+		versions, tagOf, err := upstream.variantTags(tags)
+		if tc.shouldErr {
+			require.Error(t, err, tc.variant)
+			continue
+		}
+
+		require.NoError(t, err, tc.variant)
+		require.Equal(t, tc.expectedVersions, versions, tc.variant)
+		for i, version := range versions {
+			require.Equal(t, tc.expectedTags[i], tagOf[version], tc.variant)
+		}
+	}
+}
+
+func TestContainerVariantLocal(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	repository := strings.TrimPrefix(server.URL, "http://") + "/library/nginx"
+	for _, tag := range []string{"1.27.0-alpine", "1.27.1", "1.28.0-perl-alpine"} {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+
+		ref, err := name.ParseReference(repository + ":" + tag)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+	}
+
+	latest, err := Container{Registry: repository, Variant: "alpine"}.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "1.27.0-alpine", latest)
+
+	_, err = Container{Registry: repository, Variant: "alpine", Tags: Tags{TagPattern: "^(.+)$"}}.LatestVersion()
+	require.Error(t, err)
+}
@@ -50,7 +50,7 @@ func (upstream EKS) LatestVersion() (string, error) {
 		semverConstraints = ">= 0.0.0"
 	}
 
-	expectedRange, err := semver.ParseRange(semverConstraints)
+	expectedRange, err := upstream.constraintsRange(semverConstraints)
 	if err != nil {
 		return "", fmt.Errorf("invalid semver constraints range: %v: %w", upstream.Constraints, err)
 	}
@@ -73,6 +73,11 @@ func (upstream EKS) LatestVersion() (string, error) {
 	matches := r.FindAllSubmatch(body, -1)
 	for _, match := range matches {
 		versionString := string(match[1])
+		if upstream.Ignores(versionString) {
+			log.Debugf("Skipping ignored version: %v", versionString)
+			continue
+		}
+
 		version, err := semver.Parse(versionString)
 		if err != nil {
 			log.Debugf("Error parsing version %v (%v) as semver, cannot validate semver constraints", versionString, err)
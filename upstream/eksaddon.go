@@ -24,7 +24,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
-	"github.com/blang/semver/v4"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -87,7 +86,7 @@ func (upstream EKSAddon) LatestVersion() (string, error) {
 		semverConstraints = DefaultSemVerConstraints
 	}
 
-	expectedRange, err := semver.ParseRange(semverConstraints)
+	expectedRange, err := upstream.constraintsRange(semverConstraints)
 	if err != nil {
 		return "", fmt.Errorf("invalid semver constraints range: %v: %w", upstream.Constraints, err)
 	}
@@ -126,7 +125,13 @@ func (upstream EKSAddon) LatestVersion() (string, error) {
 		return "", fmt.Errorf("no default (current) version found for EKS addon %q; set kubernetesVersion, or set latest: true to consider the highest available version instead", upstream.AddonName)
 	}
 
-	return selectHighestVersion(semverConstraints, expectedRange, candidateVersions)
+	// Add-on versions are all semver pre-releases, e.g. v1.15.1-eksbuild.1, but
+	// EKS doesn't publish actual pre-releases, so none are excluded
+	if upstream.Prereleases == PrereleasesExclude {
+		upstream.Prereleases = PrereleasesDefault
+	}
+
+	return upstream.selectLatestVersion(semverConstraints, expectedRange, candidateVersions)
 }
 
 // isDefaultVersion returns whether AWS marks this add-on version as the
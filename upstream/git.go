@@ -0,0 +1,214 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/zeitgeist/pkg/container"
+)
+
+// Git upstream representation, for any git remote, e.g. Gerrit, cgit,
+// Bitbucket Server or Gitea.
+type Git struct {
+	Base `mapstructure:",squash"`
+
+	// Git remote URL, e.g. https://gerrit.example.com/tool or
+	// git@git.example.com:team/tool.git
+	URL string
+
+	// Optional: semver constraints, e.g. < 2.0.0
+	// Will have no effect if the dependency does not follow Semver
+	Constraints string
+
+	// Optional: extract versions from prefixed or decorated tags
+	Tags `mapstructure:",squash"`
+
+	// If branch is specified, the version should be a commit SHA
+	// Will look for new commits on the branch
+	Branch string
+
+	// Optional: how commit SHAs are reported
+	Commits `mapstructure:",squash"`
+
+	// Optional: name of the credentials to use in the registry credentials file,
+	// for HTTP remotes, instead of the credentials of the remote host
+	Auth string
+
+	// Optional: private key file for SSH remotes, instead of the SSH agent
+	SSHKey string
+}
+
+const (
+	// GitUserName is the environment variable holding the username of HTTP
+	// git remotes without credentials in the credentials file.
+	GitUserName = "GIT_USERNAME"
+	// GitPassword is the environment variable holding the password or token
+	// going with GitUserName.
+	GitPassword = "GIT_PASSWORD"
+	// GitSSHKeyPassphrase is the environment variable holding the passphrase
+	// of the SSHKey.
+	GitSSHKeyPassphrase = "GIT_SSH_KEY_PASSPHRASE"
+)
+
+// LatestVersion returns the highest tag of the git remote (depending on the
+// Constraints if set), or the head of the Branch if set.
+//
+// HTTP remotes are authenticated with the registry credentials file, or the
+// GIT_USERNAME and GIT_PASSWORD environment variables. SSH remotes use the
+// SSHKey, or the SSH agent.
+func (upstream Git) LatestVersion() (string, error) { //nolint:gocritic
+	log.Debug("Using git flavour")
+	return latestGitVersion(&upstream)
+}
+
+func latestGitVersion(upstream *Git) (string, error) {
+	if upstream.URL == "" {
+		return "", errors.New("invalid git upstream: missing url")
+	}
+
+	auth, err := remoteAuth(upstream)
+	if err != nil {
+		return "", err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{upstream.URL},
+	})
+
+	log.Debugf("Listing references of %s...", upstream.URL)
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("listing references of %s: %w", upstream.URL, err)
+	}
+
+	if upstream.Branch == "" {
+		return latestGitTag(upstream, refs)
+	}
+	return latestGitCommit(upstream, refs)
+}
+
+func latestGitTag(upstream *Git, refs []*plumbing.Reference) (string, error) {
+	semverConstraints := upstream.Constraints
+	if semverConstraints == "" {
+		// If no range is passed, just use the broadest possible range
+		semverConstraints = DefaultSemVerConstraints
+	}
+
+	expectedRange, err := upstream.constraintsRange(semverConstraints)
+	if err != nil {
+		return "", fmt.Errorf("invalid semver constraints range: %#v: %w", upstream.Constraints, err)
+	}
+
+	if upstream.MinReleaseAge > 0 {
+		log.Debugf("Git tags have no publish date, minimum release age doesn't apply to %s", upstream.URL)
+	}
+
+	var tags []string
+	for _, ref := range refs {
+		if ref.Name().IsTag() {
+			tags = append(tags, ref.Name().Short())
+		}
+	}
+
+	versions, tagOf, err := upstream.extract(tags)
+	if err != nil {
+		return "", err
+	}
+	upstream.MoreRecent = upstream.comparingOutputs(upstream.MoreRecent, tagOf)
+
+	version, err := upstream.selectLatestVersion(upstream.Constraints, expectedRange, versions)
+	if err != nil {
+		return "", err
+	}
+
+	return upstream.output(version, tagOf)
+}
+
+func latestGitCommit(upstream *Git, refs []*plumbing.Reference) (string, error) {
+	branch := plumbing.NewBranchReferenceName(upstream.Branch)
+	for _, ref := range refs {
+		if ref.Name() == branch {
+			// Listing references doesn't tell commit dates, so any other head
+			// than the current commit is proposed
+			return upstream.newerCommit(upstream.Current, ref.Hash().String(), func(string) (time.Time, error) {
+				return time.Time{}, errors.New("commit dates are not available from git remotes")
+			})
+		}
+	}
+	return "", fmt.Errorf("branch '%s' not found", upstream.Branch)
+}
+
+// remoteAuth returns the authentication for the git remote: basic auth for
+// HTTP remotes with credentials, a private key or the agent for SSH remotes.
+func remoteAuth(upstream *Git) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(upstream.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid git url: %s: %w", upstream.URL, err)
+	}
+
+	switch endpoint.Protocol {
+	case "ssh":
+		user := endpoint.User
+		if user == "" {
+			user = "git"
+		}
+
+		if upstream.SSHKey != "" {
+			auth, err := gitssh.NewPublicKeysFromFile(user, upstream.SSHKey, os.Getenv(GitSSHKeyPassphrase))
+			if err != nil {
+				return nil, fmt.Errorf("reading SSH key %s: %w", upstream.SSHKey, err)
+			}
+			return auth, nil
+		}
+
+		auth, err := gitssh.NewSSHAgentAuth(user)
+		if err != nil {
+			return nil, fmt.Errorf("using the SSH agent, set an sshKey otherwise: %w", err)
+		}
+		return auth, nil
+	case "http", "https":
+		name := upstream.Auth
+		if name == "" {
+			name = endpoint.Host
+		}
+
+		username, password, err := container.BasicCredentials(name, upstream.Auth != "", GitUserName, GitPassword)
+		if err != nil {
+			return nil, err
+		}
+		if username == "" && password == "" {
+			return nil, nil
+		}
+		return &githttp.BasicAuth{Username: username, Password: password}, nil
+	default:
+		return nil, nil
+	}
+}
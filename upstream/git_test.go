@@ -0,0 +1,211 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upstream
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"sigs.k8s.io/zeitgeist/pkg/container"
+)
+
+// newBareRepository creates a bare repository with tags and a master branch in
+// dir, and returns the SHA of the head of master.
+func newBareRepository(t *testing.T, dir string) string {
+	t.Helper()
+
+	repo, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	var head string
+	for i, tag := range []string{"v1.0.0", "v1.1.0", "v2.0.0", "api/v3.0.0", ""} {
+		hash, err := worktree.Commit("commit "+tag, &git.CommitOptions{
+			AllowEmptyCommits: true,
+			Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(int64(i), 0)},
+		})
+		require.NoError(t, err)
+		head = hash.String()
+
+		if tag != "" {
+			_, err = repo.CreateTag(tag, hash, nil)
+			require.NoError(t, err)
+		}
+	}
+
+	_, err = git.PlainClone(dir, true, &git.CloneOptions{URL: worktree.Filesystem.Root()})
+	require.NoError(t, err)
+
+	return head
+}
+
+func TestGitLocal(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required to serve local repositories")
+	}
+
+	dir := filepath.Join(t.TempDir(), "repo.git")
+	head := newBareRepository(t, dir)
+
+	for _, tc := range []struct {
+		name     string
+		upstream Git
+		expected string
+	}{
+		{name: "highest tag", upstream: Git{URL: dir}, expected: "v2.0.0"},
+		{name: "constraints", upstream: Git{URL: dir, Constraints: "< 2.0.0"}, expected: "v1.1.0"},
+		{name: "tag pattern", upstream: Git{URL: dir, Tags: Tags{TagPattern: `^api/v(.+)$`}}, expected: "3.0.0"},
+		{name: "file url", upstream: Git{URL: "file://" + dir}, expected: "v2.0.0"},
+		{name: "branch", upstream: Git{URL: dir, Branch: "master"}, expected: head},
+		{name: "short sha", upstream: Git{URL: dir, Branch: "master", Commits: Commits{ShortSHA: "7"}}, expected: head[:7]},
+		{
+			name:     "current commit",
+			upstream: Git{Base: Base{Filter: Filter{Current: head[:10]}}, URL: dir, Branch: "master", Commits: Commits{ShortSHA: "7"}},
+			expected: head[:10],
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			latestVersion, err := tc.upstream.LatestVersion()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, latestVersion)
+		})
+	}
+
+	_, err := Git{URL: dir, Branch: "missing"}.LatestVersion()
+	require.Error(t, err)
+
+	_, err = Git{URL: filepath.Join(t.TempDir(), "missing.git")}.LatestVersion()
+	require.Error(t, err)
+
+	_, err = Git{}.LatestVersion()
+	require.Error(t, err)
+}
+
+func TestGitHTTPAuthLocal(t *testing.T) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is required to serve local repositories")
+	}
+
+	root := t.TempDir()
+	newBareRepository(t, filepath.Join(root, "repo.git"))
+
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if username, password, ok := req.BasicAuth(); !ok || username != "user" || password != "secret" {
+			rw.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(rw, req)
+	}))
+	defer server.Close()
+
+	t.Setenv(container.RegistryCredentialsFile, "")
+	t.Setenv(GitUserName, "")
+	t.Setenv(GitPassword, "")
+
+	g := Git{URL: server.URL + "/repo.git"}
+
+	_, err = g.LatestVersion()
+	require.Error(t, err)
+
+	t.Setenv(GitUserName, "user")
+	t.Setenv(GitPassword, "secret")
+	latestVersion, err := g.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", latestVersion)
+
+	t.Setenv(GitUserName, "")
+	t.Setenv(GitPassword, "")
+
+	credentialsFile := filepath.Join(t.TempDir(), "credentials.yaml")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte("gerrit:\n  username: user\n  password: secret\n"), 0o600))
+	t.Setenv(container.RegistryCredentialsFile, credentialsFile)
+
+	g.Auth = "gerrit"
+	latestVersion, err = g.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", latestVersion)
+
+	g.Auth = "unknown"
+	_, err = g.LatestVersion()
+	require.Error(t, err)
+}
+
+func TestGitRemoteAuth(t *testing.T) {
+	t.Setenv(container.RegistryCredentialsFile, "")
+	t.Setenv(GitUserName, "")
+	t.Setenv(GitPassword, "")
+
+	auth, err := remoteAuth(&Git{URL: "https://git.example.com/tool"})
+	require.NoError(t, err)
+	require.Nil(t, auth)
+
+	t.Setenv(GitUserName, "user")
+	t.Setenv(GitPassword, "token")
+	auth, err = remoteAuth(&Git{URL: "https://git.example.com/tool"})
+	require.NoError(t, err)
+	require.Equal(t, &githttp.BasicAuth{Username: "user", Password: "token"}, auth)
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600))
+
+	for url, user := range map[string]string{
+		"git.example.com:team/tool.git":           "git",
+		"deploy@git.example.com:team/tool.git":    "deploy",
+		"ssh://deploy@git.example.com:29418/tool": "deploy",
+		"ssh://git.example.com/team/tool.git":     "git",
+	} {
+		auth, err := remoteAuth(&Git{URL: url, SSHKey: keyFile})
+		require.NoError(t, err)
+		require.IsType(t, &gitssh.PublicKeys{}, auth)
+		require.Equal(t, user, auth.(*gitssh.PublicKeys).User)
+	}
+
+	_, err = remoteAuth(&Git{URL: "git.example.com:team/tool.git", SSHKey: filepath.Join(t.TempDir(), "missing")})
+	require.Error(t, err)
+
+	auth, err = remoteAuth(&Git{URL: "/srv/git/tool.git"})
+	require.NoError(t, err)
+	require.Nil(t, auth)
+}
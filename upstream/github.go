@@ -17,10 +17,22 @@ limitations under the License.
 package upstream
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
-	"github.com/blang/semver/v4"
+	gogithub "github.com/google/go-github/v88/github"
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/release-sdk/github"
@@ -30,18 +42,46 @@ import (
 type Github struct {
 	Base `mapstructure:",squash"`
 
-	// Github URL, e.g. hashicorp/terraform or helm/helm
+	// GitHub Enterprise Server, e.g. https://github.example.com, defaults to github.com
+	Server string
+
+	// Github URL, e.g. hashicorp/terraform or helm/helm, optionally prefixed by
+	// the host of a GitHub Enterprise Server, e.g. github.example.com/team/tool
 	URL string
 
 	// Optional: semver constraints, e.g. < 2.0.0
 	// Will have no effect if the dependency does not follow Semver
 	Constraints string
 
+	// Optional: extract versions from prefixed or decorated tags
+	Tags `mapstructure:",squash"`
+
 	// If branch is specified, the version should be a commit SHA
 	// Will look for new commits on the branch
 	Branch string
+
+	// Optional: how commit SHAs are reported
+	Commits `mapstructure:",squash"`
+
+	// Optional: "true" to also return the commit the release tag points to, as
+	// the commit companion, e.g. for `uses: owner/repo@<sha> # v1.2.3`
+	PinCommit string
+
+	// Optional: name of a release asset that must be attached for a release to
+	// be proposed, as a template using the .Tag and the .Version without its
+	// leading v, e.g. terraform_{{ .Version }}_linux_amd64.zip. Wildcards such as
+	// * are supported.
+	Asset string
+
+	// Optional: name of the release's checksum file, as a template like Asset,
+	// e.g. terraform_{{ .Version }}_SHA256SUMS. The SHA-256 it lists for the
+	// asset is returned as the sha256 companion.
+	Checksums string
 }
 
+// SHA256Companion is the companion value of the SHA-256 of a release asset.
+const SHA256Companion = "sha256"
+
 // LatestVersion returns the latest non-draft, non-prerelease Github Release
 // for the given repository (depending on the Constraints if set).
 //
@@ -50,27 +90,77 @@ type Github struct {
 // The Github API allows unauthenticated requests, but the API limits are very
 // strict: https://developer.github.com/v3/#rate-limiting
 //
-// To authenticate your requests, use the GITHUB_ACCESS_TOKEN environment variable.
+// To authenticate your requests, use the GITHUB_ACCESS_TOKEN environment variable,
+// or GITHUB_ENTERPRISE_TOKEN for GitHub Enterprise Server.
 func (upstream Github) LatestVersion() (string, error) {
+	version, _, err := upstream.LatestVersionCompanions()
+	return version, err
+}
+
+// LatestVersionCompanions returns the latest version like LatestVersion, along
+// with the sha256 of its asset when Checksums is set.
+func (upstream Github) LatestVersionCompanions() (string, map[string]string, error) {
 	log.Debug("Using GitHub flavour")
 	return latestVersion(upstream)
 }
 
-func latestVersion(upstream Github) (string, error) {
+// GithubEnterpriseTokenEnvKey is the environment variable holding the token of
+// GitHub Enterprise Server.
+const GithubEnterpriseTokenEnvKey = "GITHUB_ENTERPRISE_TOKEN"
+
+// repository returns a client for the server of the upstream, and the owner and
+// name of its repository.
+func (upstream *Github) repository() (client *github.GitHub, owner, repo string, err error) {
+	server, path := upstream.Server, strings.TrimPrefix(strings.TrimPrefix(upstream.URL, "https://"), "http://")
+
+	// GitHub owners can't contain dots, so a leading segment with one is a host
+	if host, rest, found := strings.Cut(path, "/"); found && strings.Contains(host, ".") {
+		if server == "" && host != "github.com" {
+			server = "https://" + host
+		} else if parsed, err := url.Parse(server); err != nil || (server != "" && parsed.Host != host) {
+			return nil, "", "", fmt.Errorf("github repo %s is not on server %s", upstream.URL, server)
+		}
+		path = rest
+	}
+
+	owner, rest, found := strings.Cut(path, "/")
+	repo, _, _ = strings.Cut(rest, "/")
+	if !found || owner == "" || repo == "" {
+		return nil, "", "", fmt.Errorf(
+			"invalid github repo: %s\nGithub repo should be in the form owner/repo e.g., kubernetes/kubernetes",
+			upstream.URL,
+		)
+	}
+
+	if server == "" {
+		return github.New(), owner, repo, nil
+	}
+
+	log.Debugf("Using GitHub Enterprise Server %s", server)
+	client, err = github.NewEnterpriseWithToken(server, server, os.Getenv(GithubEnterpriseTokenEnvKey))
+	if err != nil {
+		return nil, "", "", fmt.Errorf("configuring GitHub Enterprise client for %s: %w", server, err)
+	}
+
+	return client, owner, repo, nil
+}
+
+func latestVersion(upstream Github) (string, map[string]string, error) {
 	if upstream.Branch == "" {
 		return latestRelease(upstream)
 	}
-	return latestCommit(upstream)
+	version, err := latestCommit(upstream)
+	return version, nil, err
 }
 
-func latestRelease(upstream Github) (string, error) {
-	client := github.New()
+func latestRelease(upstream Github) (string, map[string]string, error) {
+	client, owner, repo, err := upstream.repository()
+	if err != nil {
+		return "", nil, err
+	}
 
-	if !strings.Contains(upstream.URL, "/") {
-		return "", fmt.Errorf(
-			"invalid github repo: %s\nGithub repo should be in the form owner/repo e.g., kubernetes/kubernetes",
-			upstream.URL,
-		)
+	if upstream.Checksums != "" && upstream.Asset == "" {
+		return "", nil, errors.New("invalid github upstream: checksums require an asset")
 	}
 
 	semverConstraints := upstream.Constraints
@@ -79,19 +169,15 @@ func latestRelease(upstream Github) (string, error) {
 		semverConstraints = DefaultSemVerConstraints
 	}
 
-	expectedRange, err := semver.ParseRange(semverConstraints)
+	expectedRange, err := upstream.constraintsRange(semverConstraints)
 	if err != nil {
-		return "", fmt.Errorf("invalid semver constraints range: %#v: %w", upstream.Constraints, err)
+		return "", nil, fmt.Errorf("invalid semver constraints range: %#v: %w", upstream.Constraints, err)
 	}
 
-	splitURL := strings.Split(upstream.URL, "/")
-	owner := splitURL[0]
-	repo := splitURL[1]
-
 	log.Debugf("Retrieving repository information for %s/%s...", owner, repo)
 	repoInfo, err := client.GetRepository(owner, repo)
 	if err != nil {
-		return "", fmt.Errorf("retrieving GitHub repository: %w", err)
+		return "", nil, fmt.Errorf("retrieving GitHub repository: %w", err)
 	}
 
 	if repoInfo.GetArchived() {
@@ -99,6 +185,7 @@ func latestRelease(upstream Github) (string, error) {
 	}
 
 	var tags []string
+	releaseOf := make(map[string]*gogithub.RepositoryRelease)
 	// We'll need to fetch all releases, as Github doesn't provide sorting options.
 	// If we don't do that, we risk running into the case where for example:
 	// - Version 1.0.0 and 2.0.0 exist
@@ -106,16 +193,20 @@ func latestRelease(upstream Github) (string, error) {
 	//
 	// Now the "latest" (date-wise) release is not the highest semver, and not necessarily the one we want
 	log.Debugf("Retrieving releases for %s/%s...", owner, repo)
-	releases, err := client.Releases(owner, repo, false)
+	releases, err := client.Releases(owner, repo, true)
 	if err != nil {
-		return "", fmt.Errorf("retrieving GitHub releases: %w", err)
+		return "", nil, fmt.Errorf("retrieving GitHub releases: %w", err)
 	}
 
 	// if there is no releases we will try to get the tags, the project might just use tags to release.
 	if len(releases) == 0 {
 		gitHubTags, err := client.ListTags(owner, repo)
 		if err != nil {
-			return "", fmt.Errorf("retrieving GitHub tags: %w", err)
+			return "", nil, fmt.Errorf("retrieving GitHub tags: %w", err)
+		}
+
+		if upstream.MinReleaseAge > 0 {
+			log.Debugf("GitHub tags have no publish date, minimum release age doesn't apply to %s/%s", owner, repo)
 		}
 
 		for _, tag := range gitHubTags {
@@ -132,26 +223,219 @@ func latestRelease(upstream Github) (string, error) {
 				continue
 			}
 
+			// Releases flagged as pre-releases are only considered when included
+			if release.GetPrerelease() && upstream.Prereleases != PrereleasesInclude {
+				log.Debugf("Skipping flagged pre-release: %s\n", release.GetTagName())
+				continue
+			}
+
+			if upstream.skipsPrerelease(release.GetTagName()) {
+				log.Debugf("Skipping pre-release: %s\n", release.GetTagName())
+				continue
+			}
+
+			if upstream.tooRecent(release.GetPublishedAt().Time) {
+				log.Debugf("Skipping release published less than %s ago: %s", upstream.MinReleaseAge, release.GetTagName())
+				continue
+			}
+
 			tags = append(tags, release.GetTagName())
+			releaseOf[release.GetTagName()] = release
 		}
 	}
 
-	return selectHighestVersion(upstream.Constraints, expectedRange, tags)
+	versions, tagOf, err := upstream.extract(tags)
+	if err != nil {
+		return "", nil, err
+	}
+	upstream.MoreRecent = upstream.comparingOutputs(upstream.MoreRecent, tagOf)
+
+	// Releases are often tagged before their binaries are uploaded, so move on
+	// to the next version until one has its assets
+	for {
+		version, err := upstream.selectLatestVersion(upstream.Constraints, expectedRange, versions)
+		if err != nil {
+			return "", nil, err
+		}
+
+		output, err := upstream.output(version, tagOf)
+		if err != nil {
+			return "", nil, err
+		}
+
+		tag, ok := tagOf[version]
+		if !ok {
+			tag = version
+		}
+
+		if upstream.Asset == "" {
+			return upstream.pinnedCommit(client, owner, repo, tag, output, nil)
+		}
+
+		release, ok := releaseOf[tag]
+		if !ok {
+			return "", nil, fmt.Errorf("checking assets of %s/%s: %s is a tag without a release", owner, repo, tag)
+		}
+
+		companions, attached, err := upstream.releaseAssets(client, owner, repo, release, version)
+		if err != nil {
+			return "", nil, err
+		}
+		if attached {
+			return upstream.pinnedCommit(client, owner, repo, tag, output, companions)
+		}
+
+		log.Infof("Skipping release %s of %s/%s, its assets are not attached yet", tag, owner, repo)
+		versions = slices.DeleteFunc(versions, func(v string) bool { return v == version })
+	}
 }
 
-func latestCommit(upstream Github) (string, error) {
-	client := github.New()
+// pinnedCommit adds the commit the tag points to to the companions of a
+// version when PinCommit is set.
+func (upstream *Github) pinnedCommit(client *github.GitHub, owner, repo, tag, output string, companions map[string]string) (string, map[string]string, error) {
+	if upstream.PinCommit == "" {
+		return output, companions, nil
+	}
 
-	if !strings.Contains(upstream.URL, "/") {
-		return "", fmt.Errorf(
-			"invalid github repo: %s\nGithub repo should be in the form owner/repo e.g., kubernetes/kubernetes",
-			upstream.URL,
-		)
+	pin, err := strconv.ParseBool(upstream.PinCommit)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid github upstream: pinCommit: %w", err)
+	}
+	if !pin {
+		return output, companions, nil
+	}
+
+	log.Debugf("Retrieving tags for %s/%s...", owner, repo)
+	tags, err := client.ListTags(owner, repo)
+	if err != nil {
+		return "", nil, fmt.Errorf("retrieving GitHub tags: %w", err)
+	}
+
+	for _, t := range tags {
+		if t.GetName() != tag {
+			continue
+		}
+
+		sha, err := upstream.shortened(t.GetCommit().GetSHA())
+		if err != nil {
+			return "", nil, err
+		}
+
+		if companions == nil {
+			companions = make(map[string]string, 1)
+		}
+		companions[CommitCompanion] = sha
+
+		return output, companions, nil
+	}
+
+	return "", nil, fmt.Errorf("tag %s of %s/%s not found", tag, owner, repo)
+}
+
+// releaseAssets checks that the release has its asset and checksum file, and
+// returns the asset's sha256 companion from the checksum file.
+func (upstream *Github) releaseAssets(client *github.GitHub, owner, repo string, release *gogithub.RepositoryRelease, version string) (companions map[string]string, attached bool, err error) {
+	data := struct{ Tag, Version string }{release.GetTagName(), strings.TrimPrefix(version, "v")}
+
+	asset, err := findAsset(release.Assets, upstream.Asset, data)
+	if err != nil || asset == nil {
+		return nil, false, err
+	}
+
+	if upstream.Checksums == "" {
+		return nil, true, nil
+	}
+
+	checksums, err := findAsset(release.Assets, upstream.Checksums, data)
+	if err != nil || checksums == nil {
+		return nil, false, err
+	}
+
+	log.Debugf("Downloading checksum file %s of %s/%s...", checksums.GetName(), owner, repo)
+	body, _, err := client.Client().DownloadReleaseAsset(context.Background(), owner, repo, checksums.GetID())
+	if err != nil {
+		return nil, false, fmt.Errorf("downloading checksum file %s: %w", checksums.GetName(), err)
+	}
+	defer body.Close()
+
+	sum, err := checksumOf(body, asset.GetName())
+	if err != nil {
+		return nil, false, fmt.Errorf("reading checksum file %s: %w", checksums.GetName(), err)
+	}
+
+	return map[string]string{SHA256Companion: sum}, true, nil
+}
+
+// findAsset returns the first asset whose name matches the rendered template,
+// or nil if there is none.
+func findAsset(assets []*gogithub.ReleaseAsset, nameTemplate string, data any) (*gogithub.ReleaseAsset, error) {
+	tmpl, err := template.New("asset").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset template %q: %w", nameTemplate, err)
+	}
+
+	var pattern strings.Builder
+	if err := tmpl.Execute(&pattern, data); err != nil {
+		return nil, fmt.Errorf("rendering asset template %q: %w", nameTemplate, err)
+	}
+
+	for _, asset := range assets {
+		matched, err := path.Match(pattern.String(), asset.GetName())
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern.String(), err)
+		}
+		if matched {
+			return asset, nil
+		}
+	}
+
+	log.Debugf("No asset matching %s", pattern.String())
+	return nil, nil
+}
+
+// sha256Hex matches SHA-256 checksums.
+var sha256Hex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// checksumOf returns the SHA-256 of a file from a checksum file, in the format
+// of sha256sum, i.e. a checksum and a file name per line. A checksum file with
+// a single checksum and no file name applies to the file.
+func checksumOf(checksums io.Reader, name string) (string, error) {
+	var single []string
+
+	scanner := bufio.NewScanner(checksums)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !sha256Hex.MatchString(fields[0]) {
+			continue
+		}
+
+		if len(fields) == 1 {
+			single = append(single, fields[0])
+			continue
+		}
+
+		// sha256sum marks files read in binary mode with *
+		file := strings.TrimPrefix(strings.TrimPrefix(fields[1], "*"), "./")
+		if file == name {
+			return strings.ToLower(fields[0]), nil
+		}
 	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	if len(single) == 1 {
+		return strings.ToLower(single[0]), nil
+	}
+
+	return "", fmt.Errorf("no SHA-256 checksum for %s", name)
+}
 
-	splitURL := strings.Split(upstream.URL, "/")
-	owner := splitURL[0]
-	repo := splitURL[1]
+func latestCommit(upstream Github) (string, error) {
+	client, owner, repo, err := upstream.repository()
+	if err != nil {
+		return "", err
+	}
 
 	branches, err := client.ListBranches(owner, repo)
 	if err != nil {
@@ -159,7 +443,13 @@ func latestCommit(upstream Github) (string, error) {
 	}
 	for _, branch := range branches {
 		if branch.GetName() == upstream.Branch {
-			return *branch.GetCommit().SHA, nil
+			return upstream.newerCommit(upstream.Current, branch.GetCommit().GetSHA(), func(sha string) (time.Time, error) {
+				commit, _, err := client.Client().GetRepoCommit(context.Background(), owner, repo, sha)
+				if err != nil {
+					return time.Time{}, err
+				}
+				return commit.GetCommit().GetCommitter().GetDate().Time, nil
+			})
 		}
 	}
 	return "", fmt.Errorf("branch '%s' not found", upstream.Branch)
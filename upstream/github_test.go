@@ -17,6 +17,10 @@ limitations under the License.
 package upstream
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/blang/semver/v4"
@@ -154,3 +158,244 @@ func TestSelectHighestVersionUnorderedWithConstraints(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "v1.29.2", v)
 }
+
+func TestGithubRepository(t *testing.T) {
+	for _, tc := range []struct {
+		server, url, owner, repo string
+		enterprise, fail         bool
+	}{
+		{url: "helm/helm", owner: "helm", repo: "helm"},
+		{url: "github.com/helm/helm", owner: "helm", repo: "helm"},
+		{url: "https://github.com/helm/helm/", owner: "helm", repo: "helm"},
+		{url: "github.example.com/team/tool", owner: "team", repo: "tool", enterprise: true},
+		{server: "https://github.example.com", url: "team/tool", owner: "team", repo: "tool", enterprise: true},
+		{server: "https://github.example.com", url: "github.example.com/team/tool", owner: "team", repo: "tool", enterprise: true},
+		{server: "https://github.example.com", url: "github.com/helm/helm", fail: true},
+		{url: "github.example.com/team", fail: true},
+		{url: "helm", fail: true},
+	} {
+		gh := Github{Server: tc.server, URL: tc.url}
+
+		client, owner, repo, err := gh.repository()
+		if tc.fail {
+			require.Error(t, err, tc.url)
+			continue
+		}
+		require.NoError(t, err, tc.url)
+		require.NotNil(t, client)
+		require.Equal(t, tc.owner, owner, tc.url)
+		require.Equal(t, tc.repo, repo, tc.url)
+	}
+}
+
+func TestGithubEnterpriseLocal(t *testing.T) {
+	t.Setenv(GithubEnterpriseTokenEnvKey, "ghe-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer ghe-token" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch req.URL.Path {
+		case "/api/v3/repos/team/tool":
+			fmt.Fprint(rw, `{"name": "tool"}`)
+		case "/api/v3/repos/team/tool/releases":
+			fmt.Fprint(rw, `[{"tag_name": "v1.2.0"}, {"tag_name": "v1.10.0"}, {"tag_name": "v1.3.0"}]`)
+		case "/api/v3/repos/team/tool/branches":
+			fmt.Fprint(rw, `[{"name": "main", "commit": {"sha": "0123456789abcdef"}}]`)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	gh := Github{
+		Server: server.URL,
+		URL:    "team/tool",
+	}
+
+	latestVersion, err := gh.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "v1.10.0", latestVersion)
+
+	gh.Branch = "main"
+	latestVersion, err = gh.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "0123456789abcdef", latestVersion)
+}
+
+func TestGithubAssetsLocal(t *testing.T) {
+	checksums := `0000000000000000000000000000000000000000000000000000000000000000  tool_1.2.0_darwin_arm64.tar.gz
+ABCDEF0123456789abcdef0123456789abcdef0123456789abcdef0123456789 *tool_1.2.0_linux_amd64.tar.gz
+`
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v3/repos/team/tool":
+			fmt.Fprint(rw, `{"name": "tool"}`)
+		case "/api/v3/repos/team/tool/releases":
+			// 1.3.0 is tagged, but its binaries aren't uploaded yet
+			fmt.Fprint(rw, `[
+  {"tag_name": "v1.3.0", "assets": [{"id": 3, "name": "tool_1.3.0_SHA256SUMS"}]},
+  {"tag_name": "v1.2.0", "assets": [
+    {"id": 1, "name": "tool_1.2.0_linux_amd64.tar.gz"},
+    {"id": 2, "name": "tool_1.2.0_SHA256SUMS"}
+  ]}
+]`)
+		case "/api/v3/repos/team/tool/releases/assets/2":
+			fmt.Fprint(rw, checksums)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	gh := Github{
+		Server: server.URL,
+		URL:    "team/tool",
+	}
+
+	latestVersion, companions, err := gh.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "v1.3.0", latestVersion)
+	require.Nil(t, companions)
+
+	gh.Asset = "tool_{{ .Version }}_linux_*.tar.gz"
+	latestVersion, companions, err = gh.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.0", latestVersion)
+	require.Nil(t, companions)
+
+	gh.Checksums = "tool_{{ .Version }}_SHA256SUMS"
+	latestVersion, companions, err = gh.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.0", latestVersion)
+	require.Equal(t, map[string]string{"sha256": "abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"}, companions)
+
+	gh.Asset = "tool_{{ .Version }}_windows_amd64.zip"
+	_, err = gh.LatestVersion()
+	require.Error(t, err)
+
+	gh.Asset = ""
+	_, err = gh.LatestVersion()
+	require.Error(t, err)
+}
+
+func TestGithubPrereleasesLocal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v3/repos/team/tool":
+			fmt.Fprint(rw, `{"name": "tool"}`)
+		case "/api/v3/repos/team/tool/releases":
+			fmt.Fprint(rw, `[
+  {"tag_name": "v1.4.0-rc.1", "prerelease": true},
+  {"tag_name": "v1.3.0", "prerelease": true},
+  {"tag_name": "v1.2.0"}
+]`)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	for prereleases, expected := range map[Prereleases]string{
+		PrereleasesDefault: "v1.2.0",
+		PrereleasesExclude: "v1.2.0",
+		PrereleasesInclude: "v1.4.0-rc.1",
+	} {
+		gh := Github{
+			Server: server.URL,
+			URL:    "team/tool",
+		}
+		gh.Prereleases = prereleases
+
+		latestVersion, err := gh.LatestVersion()
+		require.NoError(t, err)
+		require.Equal(t, expected, latestVersion, prereleases)
+	}
+}
+
+func TestChecksumOf(t *testing.T) {
+	sum := strings.Repeat("a", 64)
+
+	got, err := checksumOf(strings.NewReader(sum+"  ./tool.zip\n"), "tool.zip")
+	require.NoError(t, err)
+	require.Equal(t, sum, got)
+
+	got, err = checksumOf(strings.NewReader(sum+"\n"), "tool.zip")
+	require.NoError(t, err)
+	require.Equal(t, sum, got)
+
+	_, err = checksumOf(strings.NewReader(sum+"  other.zip\n"), "tool.zip")
+	require.Error(t, err)
+}
+
+func TestGithubCommitsLocal(t *testing.T) {
+	head := "2222222222222222222222222222222222222222"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v3/repos/team/tool":
+			fmt.Fprint(rw, `{"name": "tool"}`)
+		case "/api/v3/repos/team/tool/releases":
+			fmt.Fprint(rw, `[{"tag_name": "v1.2.0"}, {"tag_name": "v1.3.0"}]`)
+		case "/api/v3/repos/team/tool/tags":
+			fmt.Fprint(rw, `[
+  {"name": "v1.3.0", "commit": {"sha": "3333333333333333333333333333333333333333"}},
+  {"name": "v1.2.0", "commit": {"sha": "1111111111111111111111111111111111111111"}}
+]`)
+		case "/api/v3/repos/team/tool/branches":
+			fmt.Fprintf(rw, `[{"name": "main", "commit": {"sha": %q}}]`, head)
+		case "/api/v3/repos/team/tool/commits/1111111":
+			fmt.Fprint(rw, `{"commit": {"committer": {"date": "2024-01-01T00:00:00Z"}}}`)
+		case "/api/v3/repos/team/tool/commits/" + head:
+			fmt.Fprint(rw, `{"commit": {"committer": {"date": "2024-02-01T00:00:00Z"}}}`)
+		case "/api/v3/repos/team/tool/commits/4444444":
+			fmt.Fprint(rw, `{"commit": {"committer": {"date": "2024-03-01T00:00:00Z"}}}`)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	gh := Github{
+		Server:  server.URL,
+		URL:     "team/tool",
+		Branch:  "main",
+		Commits: Commits{ShortSHA: "7"},
+	}
+
+	latestVersion, err := gh.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "2222222", latestVersion)
+
+	gh.Current = "1111111"
+	latestVersion, err = gh.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "2222222", latestVersion)
+
+	// The current commit is more recent than the head, e.g. after a reset
+	gh.Current = "4444444"
+	latestVersion, err = gh.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "4444444", latestVersion)
+
+	gh = Github{
+		Server:    server.URL,
+		URL:       "team/tool",
+		PinCommit: "true",
+	}
+
+	latestVersion, companions, err := gh.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "v1.3.0", latestVersion)
+	require.Equal(t, map[string]string{"commit": "3333333333333333333333333333333333333333"}, companions)
+
+	gh.Constraints = "< 1.3.0"
+	gh.ShortSHA = "12"
+	latestVersion, companions, err = gh.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.0", latestVersion)
+	require.Equal(t, map[string]string{"commit": "111111111111"}, companions)
+}
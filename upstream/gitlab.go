@@ -20,8 +20,8 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/blang/semver/v4"
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/zeitgeist/pkg/gitlab"
@@ -41,9 +41,15 @@ type GitLab struct {
 	// Will have no effect if the dependency does not follow Semver
 	Constraints string
 
+	// Optional: extract versions from prefixed or decorated tags
+	Tags `mapstructure:",squash"`
+
 	// If branch is specified, the version should be a commit SHA
 	// Will look for new commits on the branch
 	Branch string
+
+	// Optional: how commit SHAs are reported
+	Commits `mapstructure:",squash"`
 }
 
 // LatestVersion returns the latest non-draft, non-prerelease GitLab Release
@@ -88,7 +94,7 @@ func latestGitLabRelease(upstream *GitLab) (string, error) {
 		semverConstraints = DefaultSemVerConstraints
 	}
 
-	expectedRange, err := semver.ParseRange(semverConstraints)
+	expectedRange, err := upstream.constraintsRange(semverConstraints)
 	if err != nil {
 		return "", fmt.Errorf("invalid semver constraints range: %#v: %w", upstream.Constraints, err)
 	}
@@ -117,6 +123,11 @@ func latestGitLabRelease(upstream *GitLab) (string, error) {
 		}
 
 		for _, tag := range gitLabTags {
+			if tag.Commit != nil && tag.Commit.CommittedDate != nil && upstream.tooRecent(*tag.Commit.CommittedDate) {
+				log.Debugf("Skipping tag committed less than %s ago: %s", upstream.MinReleaseAge, tag.Name)
+				continue
+			}
+
 			tags = append(tags, tag.Name)
 		}
 	} else {
@@ -125,11 +136,27 @@ func latestGitLabRelease(upstream *GitLab) (string, error) {
 				log.Debug("Skipping release without TagName")
 			}
 
+			if release.ReleasedAt != nil && upstream.tooRecent(*release.ReleasedAt) {
+				log.Debugf("Skipping release published less than %s ago: %s", upstream.MinReleaseAge, release.TagName)
+				continue
+			}
+
 			tags = append(tags, release.TagName)
 		}
 	}
 
-	return selectHighestVersion(upstream.Constraints, expectedRange, tags)
+	versions, tagOf, err := upstream.extract(tags)
+	if err != nil {
+		return "", err
+	}
+	upstream.MoreRecent = upstream.comparingOutputs(upstream.MoreRecent, tagOf)
+
+	version, err := upstream.selectLatestVersion(upstream.Constraints, expectedRange, versions)
+	if err != nil {
+		return "", err
+	}
+
+	return upstream.output(version, tagOf)
 }
 
 func latestGitlabCommit(upstream *GitLab) (string, error) {
@@ -165,7 +192,20 @@ func latestGitlabCommit(upstream *GitLab) (string, error) {
 	}
 	for _, branch := range branches {
 		if branch.Name == upstream.Branch {
-			return branch.Commit.ID, nil
+			return upstream.newerCommit(upstream.Current, branch.Commit.ID, func(sha string) (time.Time, error) {
+				if sha == branch.Commit.ID && branch.Commit.CommittedDate != nil {
+					return *branch.Commit.CommittedDate, nil
+				}
+
+				commit, err := client.GetCommit(owner, repo, sha)
+				if err != nil {
+					return time.Time{}, err
+				}
+				if commit.CommittedDate == nil {
+					return time.Time{}, fmt.Errorf("commit %s has no date", sha)
+				}
+				return *commit.CommittedDate, nil
+			})
 		}
 	}
 	return "", fmt.Errorf("branch '%s' not found", upstream.Branch)
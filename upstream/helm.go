@@ -17,18 +17,25 @@ limitations under the License.
 package upstream
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/blang/semver/v4"
 	log "github.com/sirupsen/logrus"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/getter"
 	repo "helm.sh/helm/v4/pkg/repo/v1"
+
+	"sigs.k8s.io/zeitgeist/pkg/container"
 )
 
 // Helm upstream representation.
@@ -44,33 +51,105 @@ type Helm struct {
 	// Optional: semver constraints, e.g. < 2.0.0
 	// Will have no effect if the dependency does not follow Semver
 	Constraints string
+
+	// Optional: name of the credentials to use in the registry credentials file,
+	// instead of the credentials of the repository or registry host
+	Auth string
+
+	// Optional: TLS files to connect to HTTP repositories, the CA bundle
+	// verifying the server, and the client certificate and key
+	CAFile   string
+	CertFile string
+	KeyFile  string
+
+	// Optional: "true" to pass the credentials to other domains, e.g. when the
+	// index is served from a different host than the repository
+	PassCredentialsAll string
+
+	// Optional: version to track, the chart's `version` (default) or the
+	// `appVersion` of the application it deploys
+	Track string
+
+	// AWSConfig is the AWS configuration to authenticate to ECR registries
+	// hosting OCI charts
+	AWSConfig *aws.Config
 }
 
+const (
+	// HelmRepoUserName is the environment variable holding the username of
+	// HTTP Helm repositories without credentials in the credentials file.
+	HelmRepoUserName = "HELM_REPO_USERNAME"
+	// HelmRepoPassword is the environment variable holding the password going
+	// with HelmRepoUserName.
+	HelmRepoPassword = "HELM_REPO_PASSWORD"
+
+	// TrackVersion tracks the version of Helm charts.
+	TrackVersion = "version"
+	// TrackAppVersion tracks the appVersion of Helm charts.
+	TrackAppVersion = "appVersion"
+
+	// ChartVersionCompanion is the companion value of the chart version.
+	ChartVersionCompanion = "chartVersion"
+	// AppVersionCompanion is the companion value of the chart appVersion.
+	AppVersionCompanion = "appVersion"
+)
+
 // LatestVersion returns the latest non-draft, non-prerelease Helm Release
 // for the given repository (depending on the Constraints if set).
 func (upstream Helm) LatestVersion() (string, error) {
+	version, _, err := upstream.LatestVersionCompanions()
+	return version, err
+}
+
+// LatestVersionCompanions returns the latest version like LatestVersion, along
+// with both the chartVersion and the appVersion of the chart.
+func (upstream Helm) LatestVersionCompanions() (string, map[string]string, error) {
 	log.Debug("Using Helm flavour")
-	return latestChartVersion(upstream)
+
+	chartVersion, err := latestChartVersion(upstream)
+	if err != nil {
+		return "", nil, err
+	}
+
+	companions := map[string]string{
+		ChartVersionCompanion: chartVersion.Version,
+		AppVersionCompanion:   chartVersion.AppVersion,
+	}
+
+	return upstream.tracked(chartVersion), companions, nil
+}
+
+// tracked returns the tracked version of a chart.
+func (upstream *Helm) tracked(chartVersion *repo.ChartVersion) string {
+	if upstream.Track == TrackAppVersion {
+		return strings.TrimPrefix(chartVersion.AppVersion, "v")
+	}
+
+	return strings.TrimPrefix(chartVersion.Version, "v")
 }
 
-func latestChartVersion(upstream Helm) (string, error) {
+func latestChartVersion(upstream Helm) (*repo.ChartVersion, error) {
 	// Sanity checking
 	if upstream.Repo == "" {
-		return "", errors.New("invalid helm upstream: missing repo argument")
+		return nil, errors.New("invalid helm upstream: missing repo argument")
 	}
 
 	if upstream.Chart == "" {
-		return "", errors.New("invalid helm upstream: missing chart argument")
+		return nil, errors.New("invalid helm upstream: missing chart argument")
+	}
+
+	if upstream.Track != "" && upstream.Track != TrackVersion && upstream.Track != TrackAppVersion {
+		return nil, fmt.Errorf("invalid helm upstream: unknown track %q, only %s and %s are supported", upstream.Track, TrackVersion, TrackAppVersion)
 	}
 	parsedRepo, err := url.Parse(upstream.Repo)
 	if err != nil {
-		return "", fmt.Errorf("invalid helm repo url: %s: %w", upstream.Repo, err)
+		return nil, fmt.Errorf("invalid helm repo url: %s: %w", upstream.Repo, err)
 	}
 	s := parsedRepo.Scheme
 	if s != "http" && s != "https" && s != "oci" {
 		// We currently only support http-based and oci repos (Helm defaults)
 		// Helm allows custom handlers via plugins, but I've never seen it in practice - could be added later if needed
-		return "", fmt.Errorf("invalid helm repo: %s, only http, https and oci are supported", upstream.Repo)
+		return nil, fmt.Errorf("invalid helm repo: %s, only http, https and oci are supported", upstream.Repo)
 	}
 
 	var useSemverConstraints bool
@@ -80,83 +159,296 @@ func latestChartVersion(upstream Helm) (string, error) {
 		useSemverConstraints = false
 	} else {
 		useSemverConstraints = true
-		validatedExpectedRange, err := semver.ParseRange(semverConstraints)
+		validatedExpectedRange, err := upstream.constraintsRange(semverConstraints)
 		if err != nil {
-			return "", fmt.Errorf("invalid semver constraints range: %#v: %w", upstream.Constraints, err)
+			return nil, fmt.Errorf("invalid semver constraints range: %#v: %w", upstream.Constraints, err)
 		}
 		expectedRange = validatedExpectedRange
 	}
 
+	var chartVersions repo.ChartVersions
+	var client *container.Container
+	var metadataLoaded bool
+	if s == "oci" {
+		client = upstream.registryClient()
+		chartVersions, err = ociChartVersions(&upstream, client)
+	} else {
+		chartVersions, err = indexChartVersions(&upstream)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if upstream.MoreRecent != nil {
+		if useSemverConstraints {
+			log.Debugf("Ignoring semver constraints (%s) for a non-semver scheme", upstream.Constraints)
+		}
+
+		// Ordering by appVersion needs the metadata of every chart
+		if client != nil && upstream.Track == TrackAppVersion {
+			for _, chartVersion := range chartVersions {
+				if err := ociChartMetadata(client, chartVersion); err != nil {
+					return nil, err
+				}
+			}
+			metadataLoaded = true
+		}
+
+		chartVersions = chartVersionsByRecency(&upstream, chartVersions)
+	}
+
+	// Iterate over versions and get the first newer version
+	// (Or the first version that matches our semver constraints, if defined)
+	// Versions are already ordered, cf https://github.com/helm/helm/blob/6a3daaa7aa5b89a150042cadcbe869b477bb62a1/pkg/repo/index.go#L344
+	for _, chartVersion := range chartVersions {
+		if client != nil && !metadataLoaded {
+			if err := ociChartMetadata(client, chartVersion); err != nil {
+				return nil, err
+			}
+		}
+
+		chartVersionStr := upstream.tracked(chartVersion)
+		if chartVersionStr == "" {
+			log.Debugf("Skipping chart version without %s: %s\n", upstream.Track, chartVersion.Version)
+			continue
+		}
+
+		if upstream.Ignores(chartVersionStr) {
+			log.Debugf("Skipping ignored release: %s\n", chartVersionStr)
+			continue
+		}
+
+		if upstream.tooRecent(chartVersion.Created) {
+			log.Debugf("Skipping release published less than %s ago: %s\n", upstream.MinReleaseAge, chartVersionStr)
+			continue
+		}
+
+		// Helm skips pre-releases unless they are included
+		prerelease, err := strconv.ParseBool(chartVersion.Annotations["artifacthub.io/prerelease"])
+		if err == nil && prerelease && upstream.Prereleases != PrereleasesInclude {
+			log.Debugf("Skipping annotated prerelease: %s\n", chartVersionStr)
+			continue
+		}
+
+		// Semver prereleases and constraints don't apply to other schemes
+		if upstream.MoreRecent == nil {
+			version, err := semver.Parse(chartVersionStr)
+			if err != nil { //nolint:gocritic
+				log.Debugf("Error parsing version %s (%#v) as semver, cannot validate semver constraints", chartVersionStr, err)
+			} else if len(version.Pre) > 0 && upstream.Prereleases != PrereleasesInclude {
+				log.Debugf("Skipping semver prerelease: %s\n", chartVersionStr)
+				continue
+			} else if useSemverConstraints && !expectedRange(version) {
+				log.Debugf("Skipping release not matching range constraints (%s): %s\n", upstream.Constraints, chartVersionStr)
+				continue
+			}
+		}
+
+		log.Debugf("Found latest matching release: %s\n", chartVersionStr)
+
+		return chartVersion, nil
+	}
+
+	// No latest version found – no versions? Only prereleases?
+	return nil, ErrNoVersion
+}
+
+// indexChartVersions retrieves the versions of a chart from the index of a
+// classic HTTP Helm repository, ordered by semver.
+func indexChartVersions(upstream *Helm) (repo.ChartVersions, error) {
 	// First, get the repo index
 	// Helm expects a cache directory, so we create a temporary one
 	cacheDir, err := os.MkdirTemp("", "zeitgeist-helm-cache")
 	if err != nil {
 		log.Errorf("failed to create temporary directory for Helm cache")
-		return "", err
+		return nil, err
 	}
 	defer os.RemoveAll(cacheDir)
 
-	cfg := repo.Entry{
-		Name: "zeitgeist",
-		URL:  upstream.Repo,
+	cfg, err := repoEntry(upstream)
+	if err != nil {
+		return nil, err
 	}
 	settings := cli.EnvSettings{
 		PluginsDirectory: "",
 		RepositoryCache:  cacheDir,
 	}
-	re, err := repo.NewChartRepository(&cfg, getter.All(&settings))
+	re, err := repo.NewChartRepository(cfg, getter.All(&settings))
 	if err != nil {
 		log.Errorf("failed to instantiate the Helm Chart Repository")
-		return "", err
+		return nil, err
 	}
 
 	log.Debugf("Downloading repo index for %s...", upstream.Repo)
 	indexFile, err := re.DownloadIndexFile()
 	if err != nil {
 		log.Errorf("failed to download index file for repo %s", upstream.Repo)
-		return "", err
+		return nil, err
 	}
 
 	log.Debugf("Loading repo index for %s...", upstream.Repo)
 	index, err := repo.LoadIndexFile(indexFile)
 	if err != nil {
 		log.Errorf("failed to load index file for repo %s", upstream.Repo)
-		return "", err
+		return nil, err
 	}
 
 	chartVersions := index.Entries[upstream.Chart]
 	if chartVersions == nil {
-		return "", fmt.Errorf("no chart for %s found in repository %s", upstream.Chart, upstream.Repo)
+		return nil, fmt.Errorf("no chart for %s found in repository %s", upstream.Chart, upstream.Repo)
 	}
 
-	// Iterate over versions and get the first newer version
-	// (Or the first version that matches our semver constraints, if defined)
-	// Versions are already ordered, cf https://github.com/helm/helm/blob/6a3daaa7aa5b89a150042cadcbe869b477bb62a1/pkg/repo/index.go#L344
-	for _, chartVersion := range chartVersions {
-		chartVersionStr := strings.TrimPrefix(chartVersion.Version, "v")
+	return chartVersions, nil
+}
 
-		prerelease, err := strconv.ParseBool(chartVersion.Annotations["artifacthub.io/prerelease"])
-		if err == nil && prerelease {
-			log.Debugf("Skipping annotated prerelease: %s\n", chartVersionStr)
-			continue
+// repoEntry returns the configuration of an HTTP repository, with its
+// credentials and TLS options.
+func repoEntry(upstream *Helm) (*repo.Entry, error) {
+	cfg := repo.Entry{
+		Name:     "zeitgeist",
+		URL:      upstream.Repo,
+		CAFile:   upstream.CAFile,
+		CertFile: upstream.CertFile,
+		KeyFile:  upstream.KeyFile,
+	}
+
+	if (upstream.CertFile == "") != (upstream.KeyFile == "") {
+		return nil, errors.New("invalid helm upstream: certFile and keyFile go together")
+	}
+
+	if upstream.PassCredentialsAll != "" {
+		passCredentialsAll, err := strconv.ParseBool(upstream.PassCredentialsAll)
+		if err != nil {
+			return nil, fmt.Errorf("invalid helm upstream: passCredentialsAll: %w", err)
 		}
+		cfg.PassCredentialsAll = passCredentialsAll
+	}
 
-		version, err := semver.Parse(chartVersionStr)
-		if err != nil { //nolint:gocritic
-			log.Debugf("Error parsing version %s (%#v) as semver, cannot validate semver constraints", chartVersionStr, err)
-		} else if len(version.Pre) > 0 {
-			log.Debugf("Skipping semver prerelease: %s\n", chartVersionStr)
-			continue
-		} else if useSemverConstraints && !expectedRange(version) {
-			log.Debugf("Skipping release not matching range constraints (%s): %s\n", upstream.Constraints, chartVersionStr)
+	username, password, err := repoCredentials(upstream)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Username = username
+	cfg.Password = password
+
+	return &cfg, nil
+}
+
+// repoCredentials returns the username and password of an HTTP repository:
+// the credentials named by Auth, those of the repository host in the
+// credentials file, or the ones from the environment.
+func repoCredentials(upstream *Helm) (username, password string, err error) {
+	name := upstream.Auth
+	if name == "" {
+		parsedRepo, err := url.Parse(upstream.Repo)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid helm repo url: %s: %w", upstream.Repo, err)
+		}
+		name = parsedRepo.Host
+	}
+
+	return container.BasicCredentials(name, upstream.Auth != "", HelmRepoUserName, HelmRepoPassword)
+}
+
+// registryClient returns a container client for the registry of OCI charts,
+// using the credentials of the upstream.
+func (upstream *Helm) registryClient() *container.Container {
+	client := container.New()
+	client.CredentialsName = upstream.Auth
+	client.AWSConfig = upstream.AWSConfig
+
+	return client
+}
+
+// ociChartVersions retrieves the versions of a chart hosted in an OCI registry
+// from the tags of its repository, e.g. oci://ghcr.io/org/charts for the
+// ghcr.io/org/charts/chart repository, ordered by semver. Tags only tell the
+// version of charts, see ociChartMetadata for the rest.
+func ociChartVersions(upstream *Helm, client *container.Container) (repo.ChartVersions, error) {
+	ref := strings.TrimSuffix(strings.TrimPrefix(upstream.Repo, "oci://"), "/") + "/" + upstream.Chart
+	log.Debugf("Retrieving tags for %s...", ref)
+	tags, err := client.ListTags(ref)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving tags of chart %s: %w", ref, err)
+	}
+
+	chartVersions := make(repo.ChartVersions, 0, len(tags))
+	for _, tag := range tags {
+		// OCI tags can't contain `+`, so Helm replaces it with `_`
+		version := strings.ReplaceAll(tag, "_", "+")
+		if _, err := semver.ParseTolerant(version); err != nil {
+			log.Debugf("Skipping tag of chart %s that is not a chart version: %s", ref, tag)
 			continue
 		}
 
-		log.Debugf("Found latest matching release: %s\n", chartVersionStr)
+		chartVersions = append(chartVersions, &repo.ChartVersion{
+			Metadata: &chart.Metadata{Name: upstream.Chart, Version: version},
+			URLs:     []string{ref + ":" + tag},
+		})
+	}
 
-		return chartVersionStr, nil
+	if len(chartVersions) == 0 {
+		return nil, fmt.Errorf("no chart for %s found in repository %s", upstream.Chart, upstream.Repo)
 	}
 
-	// No latest version found – no versions? Only prereleases?
-	return "", errors.New("no potential version found")
+	sort.Sort(sort.Reverse(chartVersions))
+
+	return chartVersions, nil
+}
+
+// ociCreatedAnnotation is the manifest annotation Helm records the creation
+// date of charts pushed to OCI registries in.
+const ociCreatedAnnotation = "org.opencontainers.image.created"
+
+// ociChartMetadata completes a chart version listed by ociChartVersions with
+// the metadata of the chart, e.g. its appVersion and annotations, read from
+// its config, and with its creation date.
+func ociChartMetadata(client *container.Container, chartVersion *repo.ChartVersion) error {
+	ref := chartVersion.URLs[0]
+	log.Debugf("Retrieving metadata of chart %s...", ref)
+	annotations, config, err := client.Artifact(ref)
+	if err != nil {
+		return fmt.Errorf("retrieving metadata of chart %s: %w", ref, err)
+	}
+
+	metadata := chart.Metadata{}
+	if err := json.Unmarshal(config, &metadata); err != nil {
+		return fmt.Errorf("decoding metadata of chart %s: %w", ref, err)
+	}
+	// The tag is the version the chart is known by
+	metadata.Name = chartVersion.Name
+	metadata.Version = chartVersion.Version
+	chartVersion.Metadata = &metadata
+
+	if created, ok := annotations[ociCreatedAnnotation]; ok {
+		chartVersion.Created, err = time.Parse(time.RFC3339, created)
+		if err != nil {
+			return fmt.Errorf("parsing creation date of chart %s: %w", ref, err)
+		}
+	}
+
+	return nil
+}
+
+// chartVersionsByRecency orders chart versions by their tracked version with MoreRecent,
+// rather than by semver as in the repository index.
+func chartVersionsByRecency(upstream *Helm, chartVersions repo.ChartVersions) repo.ChartVersions {
+	// Several charts can deploy the same appVersion, keep the newest one
+	byVersion := make(map[string]*repo.ChartVersion, len(chartVersions))
+	versions := make([]string, 0, len(chartVersions))
+	for _, chartVersion := range chartVersions {
+		version := upstream.tracked(chartVersion)
+		if _, ok := byVersion[version]; ok {
+			continue
+		}
+		byVersion[version] = chartVersion
+		versions = append(versions, version)
+	}
+
+	sorted := make(repo.ChartVersions, 0, len(chartVersions))
+	for _, version := range upstream.byRecency(versions) {
+		sorted = append(sorted, byVersion[version])
+	}
+
+	return sorted
 }
@@ -17,14 +17,34 @@ limitations under the License.
 package upstream
 
 import (
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
-
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/stretchr/testify/require"
 	"go.yaml.in/yaml/v3"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+
+	"sigs.k8s.io/zeitgeist/pkg/container"
 )
 
 func TestUnserialiseHelm(t *testing.T) {
@@ -228,3 +248,395 @@ func TestHelmHappyPathWithPrelease(t *testing.T) {
 	require.NotEmpty(t, latestVersion)
 	require.Equal(t, "0.1.0", latestVersion)
 }
+
+func TestHelmMinReleaseAgeLocal(t *testing.T) {
+	// The most recent chart version is published right now
+	index := fmt.Sprintf(`apiVersion: v2
+entries:
+  recent:
+  - apiVersion: v2
+    created: %q
+    name: recent
+    version: 1.1.0
+  - apiVersion: v2
+    created: "2021-01-01T00:00:00Z"
+    name: recent
+    version: 1.0.0
+`, time.Now().UTC().Format(time.RFC3339))
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, index)
+	}))
+	defer server.Close()
+
+	h := Helm{
+		Repo:  server.URL,
+		Chart: "recent",
+	}
+
+	latestVersion, err := h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "1.1.0", latestVersion)
+
+	h.MinReleaseAge = 72 * time.Hour
+
+	latestVersion, err = h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0", latestVersion)
+}
+
+func TestHelmMoreRecentLocal(t *testing.T) {
+	// As semver prereleases, r9 would be more recent than r10
+	index := `apiVersion: v2
+entries:
+  revisions:
+  - apiVersion: v2
+    created: "2021-01-01T00:00:00Z"
+    name: revisions
+    version: 1.0.0-r9
+  - apiVersion: v2
+    created: "2021-01-01T00:00:00Z"
+    name: revisions
+    version: 1.0.0-r10
+`
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, index)
+	}))
+	defer server.Close()
+
+	h := Helm{
+		Repo:  server.URL,
+		Chart: "revisions",
+	}
+	h.MoreRecent = func(a, b string) (bool, error) {
+		return revision(t, a) > revision(t, b), nil
+	}
+
+	latestVersion, err := h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0-r10", latestVersion)
+}
+
+func TestHelmPrereleasesLocal(t *testing.T) {
+	index := `apiVersion: v2
+entries:
+  channels:
+  - apiVersion: v2
+    created: "2021-01-01T00:00:00Z"
+    name: channels
+    version: 1.2.0-rc.1
+  - apiVersion: v2
+    annotations:
+      artifacthub.io/prerelease: "true"
+    created: "2021-01-01T00:00:00Z"
+    name: channels
+    version: 1.1.0
+  - apiVersion: v2
+    created: "2021-01-01T00:00:00Z"
+    name: channels
+    version: 1.0.0
+`
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, index)
+	}))
+	defer server.Close()
+
+	for prereleases, expected := range map[Prereleases]string{
+		PrereleasesDefault: "1.0.0",
+		PrereleasesExclude: "1.0.0",
+		PrereleasesInclude: "1.2.0-rc.1",
+	} {
+		h := Helm{
+			Repo:        server.URL,
+			Chart:       "channels",
+			Constraints: "^1.0",
+		}
+		h.Prereleases = prereleases
+
+		latestVersion, err := h.LatestVersion()
+		require.NoError(t, err)
+		require.Equal(t, expected, latestVersion, prereleases)
+	}
+}
+
+func TestHelmAppVersionLocal(t *testing.T) {
+	// Both 2.1.0 and 2.0.1 deploy 1.6.0, 3.0.0 deploys the next minor
+	index := `apiVersion: v2
+entries:
+  app:
+  - apiVersion: v2
+    appVersion: v1.7.0
+    created: "2021-01-01T00:00:00Z"
+    name: app
+    version: 3.0.0
+  - apiVersion: v2
+    appVersion: v1.6.0
+    created: "2021-01-01T00:00:00Z"
+    name: app
+    version: 2.1.0
+  - apiVersion: v2
+    appVersion: v1.6.0
+    created: "2021-01-01T00:00:00Z"
+    name: app
+    version: 2.0.1
+  - apiVersion: v2
+    created: "2021-01-01T00:00:00Z"
+    name: app
+    version: 2.0.0
+`
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, index)
+	}))
+	defer server.Close()
+
+	h := Helm{
+		Repo:        server.URL,
+		Chart:       "app",
+		Track:       TrackAppVersion,
+		Constraints: "< 1.7.0",
+	}
+
+	latestVersion, companions, err := h.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "1.6.0", latestVersion)
+	require.Equal(t, map[string]string{"chartVersion": "2.1.0", "appVersion": "v1.6.0"}, companions)
+
+	h.Track = TrackVersion
+	h.Constraints = "< 3.0.0"
+	latestVersion, companions, err = h.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "2.1.0", latestVersion)
+	require.Equal(t, map[string]string{"chartVersion": "2.1.0", "appVersion": "v1.6.0"}, companions)
+
+	h.Track = "digest"
+	_, err = h.LatestVersion()
+	require.Error(t, err)
+}
+
+func TestHelmOCILocal(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	repository := strings.TrimPrefix(server.URL, "http://") + "/charts"
+	for _, tag := range []string{"0.9.0_build.1", "1.0.0", "1.1.0", "2.0.0-rc.1", "latest"} {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+
+		ref, err := name.ParseReference(repository + "/podinfo:" + tag)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+	}
+
+	for _, tc := range []struct {
+		constraints string
+		prereleases Prereleases
+		expected    string
+	}{
+		{expected: "1.1.0"},
+		{constraints: "< 1.1.0", expected: "1.0.0"},
+		{constraints: "< 1.0.0", expected: "0.9.0+build.1"},
+		{prereleases: PrereleasesInclude, expected: "2.0.0-rc.1"},
+	} {
+		h := Helm{
+			Repo:        "oci://" + repository,
+			Chart:       "podinfo",
+			Constraints: tc.constraints,
+		}
+		h.Prereleases = tc.prereleases
+
+		latestVersion, err := h.LatestVersion()
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, latestVersion)
+	}
+
+	_, err := Helm{Repo: "oci://" + repository, Chart: "missing"}.LatestVersion()
+	require.Error(t, err)
+}
+
+// chartManifest is the manifest of a Helm chart pushed to an OCI registry.
+type chartManifest struct {
+	manifest []byte
+}
+
+func (c chartManifest) RawManifest() ([]byte, error) {
+	return c.manifest, nil
+}
+
+func (c chartManifest) MediaType() (types.MediaType, error) {
+	return types.OCIManifestSchema1, nil
+}
+
+// pushChart pushes a chart with the given metadata and creation date to ref,
+// reduced to its config.
+func pushChart(t *testing.T, ref string, metadata *chart.Metadata, created time.Time) {
+	t.Helper()
+
+	parsed, err := name.ParseReference(ref)
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	config := static.NewLayer(raw, "application/vnd.cncf.helm.config.v1+json")
+	require.NoError(t, remote.WriteLayer(parsed.Context(), config))
+
+	descriptor, err := partial.Descriptor(config)
+	require.NoError(t, err)
+	manifest, err := json.Marshal(v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config:        *descriptor,
+		Layers:        []v1.Descriptor{},
+		Annotations:   map[string]string{ociCreatedAnnotation: created.Format(time.RFC3339)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, remote.Put(parsed, chartManifest{manifest: manifest}))
+}
+
+func TestHelmOCIMetadataLocal(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	repository := strings.TrimPrefix(server.URL, "http://") + "/charts"
+	pushChart(t, repository+"/app:1.0.0", &chart.Metadata{Name: "app", Version: "1.0.0", AppVersion: "1.6.0"}, time.Now().Add(-48*time.Hour))
+	pushChart(t, repository+"/app:1.1.0", &chart.Metadata{Name: "app", Version: "1.1.0", AppVersion: "1.7.0"}, time.Now())
+	pushChart(t, repository+"/app:1.2.0", &chart.Metadata{
+		Name:        "app",
+		Version:     "1.2.0",
+		AppVersion:  "1.8.0-rc.1",
+		Annotations: map[string]string{"artifacthub.io/prerelease": "true"},
+	}, time.Now())
+
+	h := Helm{Repo: "oci://" + repository, Chart: "app"}
+
+	// The annotated prerelease is skipped
+	latestVersion, companions, err := h.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "1.1.0", latestVersion)
+	require.Equal(t, map[string]string{"chartVersion": "1.1.0", "appVersion": "1.7.0"}, companions)
+
+	h.MinReleaseAge = 24 * time.Hour
+	latestVersion, err = h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0", latestVersion)
+
+	h.MinReleaseAge = 0
+	h.Track = TrackAppVersion
+	h.Constraints = "< 1.7.0"
+	latestVersion, companions, err = h.LatestVersionCompanions()
+	require.NoError(t, err)
+	require.Equal(t, "1.6.0", latestVersion)
+	require.Equal(t, map[string]string{"chartVersion": "1.0.0", "appVersion": "1.6.0"}, companions)
+
+	// Ordering by appVersion reads the metadata of every chart
+	h.Constraints = ""
+	h.MoreRecent = func(a, b string) (bool, error) {
+		return semver.MustParse(a).GT(semver.MustParse(b)), nil
+	}
+	latestVersion, err = h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "1.7.0", latestVersion)
+}
+
+func revision(t *testing.T, version string) int {
+	t.Helper()
+
+	rev, err := strconv.Atoi(strings.TrimPrefix(version, "1.0.0-r"))
+	require.NoError(t, err)
+
+	return rev
+}
+
+func TestHelmAuthLocal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if username, password, ok := req.BasicAuth(); !ok || username != "user" || password != "secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		helmHandler(rw, req)
+	}))
+	defer server.Close()
+
+	t.Setenv(container.RegistryCredentialsFile, "")
+	t.Setenv(HelmRepoUserName, "")
+	t.Setenv(HelmRepoPassword, "")
+
+	h := Helm{
+		Repo:  server.URL,
+		Chart: "dependency",
+	}
+
+	_, err := h.LatestVersion()
+	require.Error(t, err)
+
+	t.Setenv(HelmRepoUserName, "user")
+	t.Setenv(HelmRepoPassword, "secret")
+	latestVersion, err := h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "0.2.0", latestVersion)
+
+	t.Setenv(HelmRepoUserName, "")
+	t.Setenv(HelmRepoPassword, "")
+
+	credentialsFile := filepath.Join(t.TempDir(), "credentials.yaml")
+	credentials := fmt.Sprintf(`%s:
+  username: user
+  passwordEnv: HELM_TEST_PASSWORD
+private:
+  username: user
+  password: secret
+`, strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(credentials), 0o600))
+	t.Setenv(container.RegistryCredentialsFile, credentialsFile)
+
+	// The password variable isn't set yet
+	_, err = h.LatestVersion()
+	require.Error(t, err)
+
+	t.Setenv("HELM_TEST_PASSWORD", "secret")
+	latestVersion, err = h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "0.2.0", latestVersion)
+
+	h.Auth = "private"
+	latestVersion, err = h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "0.2.0", latestVersion)
+
+	h.Auth = "unknown"
+	_, err = h.LatestVersion()
+	require.Error(t, err)
+
+	h.Auth = ""
+	h.PassCredentialsAll = "sometimes"
+	_, err = h.LatestVersion()
+	require.Error(t, err)
+}
+
+func TestHelmTLSLocal(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(helmHandler))
+	defer server.Close()
+
+	h := Helm{
+		Repo:  server.URL,
+		Chart: "dependency",
+	}
+
+	_, err := h.LatestVersion()
+	require.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, ca, 0o600))
+
+	h.CAFile = caFile
+	latestVersion, err := h.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, "0.2.0", latestVersion)
+
+	h.CertFile = caFile
+	_, err = h.LatestVersion()
+	require.Error(t, err)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/blang/semver/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// Tags extracts versions from prefixed or decorated tags, e.g. api/v1.2.3,
+// cli-v2.0.1 or release-1.4.0.
+type Tags struct {
+	// Optional: regular expression that tags must match. The version is its
+	// `version` named group, or else its first group, e.g. ^api/v(?P<version>.+)$
+	TagPattern string
+
+	// Optional: template of the version to return, using the .Tag and the .Version
+	// extracted from it, e.g. "{{ .Tag }}", and the .Major and .Minor of semver
+	// versions, e.g. "v{{ .Major }}". Defaults to the extracted version.
+	VersionTemplate string
+}
+
+// extract returns the versions extracted from the tags matching TagPattern,
+// and the tag each version was extracted from. Without TagPattern, tags are
+// returned as is.
+func (t *Tags) extract(tags []string) ([]string, map[string]string, error) {
+	if t.TagPattern == "" {
+		return tags, nil, nil
+	}
+
+	pattern, err := regexp.Compile(t.TagPattern)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid tag pattern %q: %w", t.TagPattern, err)
+	}
+
+	group := pattern.SubexpIndex("version")
+	if group < 0 && pattern.NumSubexp() > 0 {
+		group = 1
+	}
+
+	versions := make([]string, 0, len(tags))
+	tagOf := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		matches := pattern.FindStringSubmatch(tag)
+		if matches == nil {
+			log.Debugf("Skipping tag not matching pattern %s: %s", t.TagPattern, tag)
+			continue
+		}
+
+		version := matches[0]
+		if group > 0 {
+			version = matches[group]
+		}
+
+		if _, ok := tagOf[version]; ok {
+			continue
+		}
+
+		versions = append(versions, version)
+		tagOf[version] = tag
+	}
+
+	return versions, tagOf, nil
+}
+
+// output returns the version to report for a version extracted by extract.
+func (t *Tags) output(version string, tagOf map[string]string) (string, error) {
+	if t.VersionTemplate == "" {
+		return version, nil
+	}
+
+	tag, ok := tagOf[version]
+	if !ok {
+		tag = version
+	}
+
+	tmpl, err := template.New("version").Option("missingkey=error").Parse(t.VersionTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid version template %q: %w", t.VersionTemplate, err)
+	}
+
+	data := struct{ Tag, Version, Major, Minor string }{Tag: tag, Version: version}
+	if v, err := semver.ParseTolerant(version); err == nil {
+		data.Major, data.Minor = strconv.FormatUint(v.Major, 10), strconv.FormatUint(v.Minor, 10)
+	}
+
+	var output strings.Builder
+	if err := tmpl.Execute(&output, data); err != nil {
+		return "", fmt.Errorf("rendering version template %q: %w", t.VersionTemplate, err)
+	}
+
+	return output.String(), nil
+}
+
+// comparingOutputs adapts a comparison of versions as stored locally, e.g. for
+// a custom version scheme, to the versions extracted by extract.
+func (t *Tags) comparingOutputs(moreRecent func(a, b string) (bool, error), tagOf map[string]string) func(a, b string) (bool, error) {
+	if moreRecent == nil || t.VersionTemplate == "" {
+		return moreRecent
+	}
+
+	return func(a, b string) (bool, error) {
+		aOutput, err := t.output(a, tagOf)
+		if err != nil {
+			return false, err
+		}
+
+		bOutput, err := t.output(b, tagOf)
+		if err != nil {
+			return false, err
+		}
+
+		return moreRecent(aOutput, bOutput)
+	}
+}
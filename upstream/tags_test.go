@@ -0,0 +1,143 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upstream
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagsExtract(t *testing.T) {
+	tags := []string{"api/v1.2.0", "api/v1.10.0", "cli/v2.0.0", "v3.0.0", "api/v1.2.0-rc.1"}
+
+	for _, tc := range []struct {
+		name             string
+		tagPattern       string
+		expectedVersions []string
+		shouldErr        bool
+	}{
+		{
+			name:             "no pattern",
+			expectedVersions: tags,
+		},
+		{
+			name:             "named group",
+			tagPattern:       `^(api)/v(?P<version>.+)$`,
+			expectedVersions: []string{"1.2.0", "1.10.0", "1.2.0-rc.1"},
+		},
+		{
+			name:             "first group",
+			tagPattern:       `^cli/(.+)$`,
+			expectedVersions: []string{"v2.0.0"},
+		},
+		{
+			name:             "whole match",
+			tagPattern:       `^v\d+\.\d+\.\d+$`,
+			expectedVersions: []string{"v3.0.0"},
+		},
+		{
+			name:       "invalid pattern",
+			tagPattern: `^api/(`,
+			shouldErr:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tagsOf := Tags{TagPattern: tc.tagPattern}
+
+			versions, _, err := tagsOf.extract(tags)
+			if tc.shouldErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedVersions, versions)
+		})
+	}
+}
+
+func TestTagsOutput(t *testing.T) {
+	expectedRange, err := semver.ParseRange(DefaultSemVerConstraints)
+	require.NoError(t, err)
+
+	tags := []string{"api/v1.2.0", "api/v1.10.0", "cli/v2.0.0"}
+
+	for _, tc := range []struct {
+		versionTemplate string
+		expected        string
+		shouldErr       bool
+	}{
+		{expected: "1.10.0"},
+		{versionTemplate: "{{ .Tag }}", expected: "api/v1.10.0"},
+		{versionTemplate: "v{{ .Version }}", expected: "v1.10.0"},
+		{versionTemplate: "v{{ .Major }}.{{ .Minor }}", expected: "v1.10"},
+		{versionTemplate: "{{ .Unknown }}", shouldErr: true},
+	} {
+		t.Run(tc.versionTemplate, func(t *testing.T) {
+			tagsOf := Tags{TagPattern: `^api/v(?P<version>.+)$`, VersionTemplate: tc.versionTemplate}
+
+			versions, tagOf, err := tagsOf.extract(tags)
+			require.NoError(t, err)
+
+			var filter Filter
+			version, err := filter.selectLatestVersion("", expectedRange, versions)
+			require.NoError(t, err)
+
+			output, err := tagsOf.output(version, tagOf)
+			if tc.shouldErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, output)
+		})
+	}
+}
+
+func TestTagsComparingOutputs(t *testing.T) {
+	tagsOf := Tags{TagPattern: `^build-(?P<version>\d+)$`, VersionTemplate: "{{ .Tag }}"}
+
+	_, tagOf, err := tagsOf.extract([]string{"build-9", "build-10"})
+	require.NoError(t, err)
+
+	var compared []string
+	moreRecent := tagsOf.comparingOutputs(func(a, b string) (bool, error) {
+		compared = append(compared, a, b)
+		return a > b, nil
+	}, tagOf)
+
+	_, err = moreRecent("10", "9")
+	require.NoError(t, err)
+	require.Equal(t, []string{"build-10", "build-9"}, compared)
+}
+
+func TestTagsDecode(t *testing.T) {
+	var github Github
+	err := mapstructure.Decode(map[string]string{
+		"flavour":         "github",
+		"url":             "kubernetes/kubernetes",
+		"tagPattern":      `^v(?P<version>.+)$`,
+		"versionTemplate": "{{ .Tag }}",
+	}, &github)
+	require.NoError(t, err)
+	require.Equal(t, `^v(?P<version>.+)$`, github.TagPattern)
+	require.Equal(t, "{{ .Tag }}", github.VersionTemplate)
+}
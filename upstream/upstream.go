@@ -26,6 +26,9 @@ package upstream
 
 import (
 	"errors"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	log "github.com/sirupsen/logrus"
@@ -33,9 +36,12 @@ import (
 	"sigs.k8s.io/release-utils/helpers"
 )
 
-// Base only contains a flavour. "Concrete" upstreams each implement their own fields.
+// Base only contains a flavour, and the Filter set from the dependency.
+// "Concrete" upstreams each implement their own fields.
 type Base struct {
 	Flavour Flavour `yaml:"flavour"`
+
+	Filter `mapstructure:"-" yaml:"-"`
 }
 
 // LatestVersion will always return an error.
@@ -44,6 +50,149 @@ func (u *Base) LatestVersion() (string, error) {
 	return "", errors.New("cannot determine latest version for Base")
 }
 
+// ErrNoVersion is returned when no upstream version matches the constraints and
+// the filter.
+var ErrNoVersion = errors.New("no potential version found")
+
+// Filter restricts which upstream releases are considered. It is set from the
+// dependency rather than the upstream, and shared by all upstreams.
+type Filter struct {
+	// Optional: minimum age of a release for it to be considered.
+	// Only applies to releases whose publish date is known.
+	MinReleaseAge time.Duration
+
+	// Optional: versions that should never be considered, either exact versions
+	// or semver ranges, e.g. 3.2.0 or ">= 3.2.0 < 3.3.0"
+	IgnoreVersions []string
+
+	// Optional: ordering of versions, for schemes semver can't parse. When set,
+	// it replaces semver ordering and constraints to select the latest version.
+	MoreRecent func(a, b string) (bool, error)
+
+	// Optional: whether pre-releases are considered. By default, each upstream
+	// keeps its own behaviour.
+	Prereleases Prereleases
+
+	// Optional: version currently in use, e.g. so that branch heads are only
+	// reported when they are more recent than the current commit
+	Current string
+}
+
+// Prereleases selects whether upstreams consider pre-releases.
+type Prereleases string
+
+const (
+	// PrereleasesDefault keeps the upstream's own behaviour.
+	PrereleasesDefault Prereleases = ""
+
+	// PrereleasesInclude considers pre-releases, and lets constraints match them.
+	PrereleasesInclude Prereleases = "include"
+
+	// PrereleasesExclude never considers pre-releases.
+	PrereleasesExclude Prereleases = "exclude"
+)
+
+// skipsPrerelease returns whether a version is an excluded semver pre-release.
+func (f *Filter) skipsPrerelease(version string) bool {
+	if f.Prereleases != PrereleasesExclude {
+		return false
+	}
+
+	parsed, err := semver.ParseTolerant(version)
+	return err == nil && len(parsed.Pre) > 0
+}
+
+// tooRecent returns whether a release published at the given time is more recent
+// than the minimum release age.
+func (f *Filter) tooRecent(published time.Time) bool {
+	return f.MinReleaseAge > 0 && !published.IsZero() && time.Since(published) < f.MinReleaseAge
+}
+
+// Ignores returns whether a version is one of the ignored versions, or matches
+// one of the ignored ranges.
+func (f *Filter) Ignores(version string) bool {
+	for _, ignored := range f.IgnoreVersions {
+		if strings.TrimPrefix(version, "v") == strings.TrimPrefix(ignored, "v") {
+			return true
+		}
+
+		ignoredRange, err := parseConstraints(ignored, false)
+		if err != nil {
+			continue
+		}
+
+		parsed, err := semver.ParseTolerant(version)
+		if err == nil && ignoredRange(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ignoring returns the versions that aren't ignored, nor excluded pre-releases.
+func (f *Filter) ignoring(versions []string) []string {
+	if len(f.IgnoreVersions) == 0 && f.Prereleases != PrereleasesExclude {
+		return versions
+	}
+
+	kept := make([]string, 0, len(versions))
+	for _, version := range versions {
+		if f.Ignores(version) {
+			log.Debugf("Skipping ignored version: %s", version)
+			continue
+		}
+		if f.skipsPrerelease(version) {
+			log.Debugf("Skipping pre-release: %s", version)
+			continue
+		}
+		kept = append(kept, version)
+	}
+
+	return kept
+}
+
+// byRecency returns the versions that MoreRecent can compare, most recent first.
+func (f *Filter) byRecency(versions []string) []string {
+	sorted := make([]string, 0, len(versions))
+	for _, version := range versions {
+		if _, err := f.MoreRecent(version, version); err != nil {
+			log.Debugf("Skipping version not matching the dependency's scheme: %s (%v)", version, err)
+			continue
+		}
+		sorted = append(sorted, version)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		moreRecent, _ := f.MoreRecent(sorted[i], sorted[j]) //nolint: errcheck // versions were checked above
+		return moreRecent
+	})
+
+	return sorted
+}
+
+// selectLatestVersion selects the latest of the tags that aren't ignored, in the
+// order of MoreRecent when set, or else the highest semver version matching
+// the constraints.
+func (f *Filter) selectLatestVersion(constraints string, expectedRange semver.Range, tags []string) (string, error) {
+	tags = f.ignoring(tags)
+
+	if f.MoreRecent == nil {
+		return selectHighestVersion(constraints, expectedRange, tags)
+	}
+
+	if constraints != "" {
+		log.Debugf("Ignoring semver constraints (%s) for a non-semver scheme", constraints)
+	}
+
+	if sorted := f.byRecency(tags); len(sorted) > 0 {
+		log.Debugf("Found latest release: %s", sorted[0])
+		return sorted[0], nil
+	}
+
+	return "", ErrNoVersion
+}
+
 // Flavour is an enum of all supported upstreams and their string representation.
 type Flavour string
 
@@ -54,6 +203,9 @@ const (
 	// GitLabFlavour is for GitLab releases.
 	GitLabFlavour Flavour = "gitlab"
 
+	// GitFlavour is for tags and branches of any git remote.
+	GitFlavour Flavour = "git"
+
 	// AMIFlavour is for Amazon Machine Images.
 	AMIFlavour Flavour = "ami"
 
@@ -107,5 +259,5 @@ func selectHighestVersion(constraints string, expectedRange semver.Range, tags [
 	}
 
 	// No latest version found – no versions? Only prereleases?
-	return "", errors.New("no potential version found")
+	return "", ErrNoVersion
 }
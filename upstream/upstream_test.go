@@ -17,8 +17,11 @@ limitations under the License.
 package upstream
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/blang/semver/v4"
 	"github.com/stretchr/testify/require"
 	"go.yaml.in/yaml/v3"
 )
@@ -34,3 +37,81 @@ func TestBaseLatestVersion(t *testing.T) {
 	_, err = u.LatestVersion()
 	require.Error(t, err)
 }
+
+func TestFilterIgnores(t *testing.T) {
+	filter := Filter{IgnoreVersions: []string{"1.2.3", "v1.3.0", ">=2.0.0 <2.1.0"}}
+
+	for version, ignored := range map[string]bool{
+		"1.2.3":  true,
+		"v1.2.3": true,
+		"1.3.0":  true,
+		"2.0.5":  true,
+		"1.2.4":  false,
+		"2.1.0":  false,
+		"latest": false,
+	} {
+		require.Equal(t, ignored, filter.Ignores(version), version)
+	}
+
+	require.Equal(t, []string{"1.2.4", "2.1.0"}, filter.ignoring([]string{"1.2.3", "1.2.4", "2.0.1", "2.1.0"}))
+}
+
+func TestFilterPrereleases(t *testing.T) {
+	versions := []string{"v1.0.0", "v1.1.0-rc.1", "latest"}
+
+	for prereleases, expected := range map[Prereleases][]string{
+		PrereleasesDefault: versions,
+		PrereleasesInclude: versions,
+		PrereleasesExclude: {"v1.0.0", "latest"},
+	} {
+		filter := Filter{Prereleases: prereleases}
+		require.Equal(t, expected, filter.ignoring(versions), prereleases)
+	}
+
+	filter := Filter{Prereleases: PrereleasesExclude}
+	require.True(t, filter.skipsPrerelease("v1.1.0-rc.1"))
+	require.False(t, filter.skipsPrerelease("v1.0.0"))
+
+	expectedRange, err := (&Filter{Prereleases: PrereleasesInclude}).constraintsRange("^1.0")
+	require.NoError(t, err)
+	require.True(t, expectedRange(semver.MustParse("1.1.0-rc.1")))
+
+	expectedRange, err = filter.constraintsRange("^1.0")
+	require.NoError(t, err)
+	require.False(t, expectedRange(semver.MustParse("1.1.0-rc.1")))
+}
+
+func TestSelectLatestVersion(t *testing.T) {
+	expectedRange, err := semver.ParseRange(DefaultSemVerConstraints)
+	require.NoError(t, err)
+
+	tags := []string{"r9", "r10", "r2", "v1.0.0", "unrelated"}
+
+	filter := Filter{}
+	latest, err := filter.selectLatestVersion("", expectedRange, tags)
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0", latest)
+
+	filter.MoreRecent = func(a, b string) (bool, error) {
+		aRev, err := strconv.Atoi(strings.TrimPrefix(a, "r"))
+		if err != nil {
+			return false, err
+		}
+		bRev, err := strconv.Atoi(strings.TrimPrefix(b, "r"))
+		if err != nil {
+			return false, err
+		}
+		return aRev > bRev, nil
+	}
+	latest, err = filter.selectLatestVersion("", expectedRange, tags)
+	require.NoError(t, err)
+	require.Equal(t, "r10", latest)
+
+	filter.IgnoreVersions = []string{"r10"}
+	latest, err = filter.selectLatestVersion("", expectedRange, tags)
+	require.NoError(t, err)
+	require.Equal(t, "r9", latest)
+
+	_, err = filter.selectLatestVersion("", expectedRange, []string{"unrelated"})
+	require.Error(t, err)
+}